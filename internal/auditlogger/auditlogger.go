@@ -5,49 +5,222 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
-	"strings"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
 
 	"cloud.google.com/go/logging"
 	mrpb "google.golang.org/genproto/googleapis/api/monitoredres"
+
+	"github.com/nais/log-tailer/pkg/pgaudit"
+)
+
+const (
+	// workerCount is how many goroutines concurrently batch and ship audit
+	// entries to GCP.
+	workerCount = 4
+	// batchSize is the maximum number of entries shipped in a single
+	// Log+Flush call.
+	batchSize = 20
+	// batchInterval bounds how long a worker waits to fill a batch before
+	// flushing whatever it has.
+	batchInterval = 2 * time.Second
+
+	// maxSendAttempts is how many times in total (the first attempt plus
+	// retries) a batch is sent before it is spilled to the dead-letter file.
+	maxSendAttempts = 5
+
+	// drainGracePeriod is how long Log waits, after ctx is cancelled, for
+	// queued entries to be shipped or dead-lettered before giving up.
+	drainGracePeriod = 10 * time.Second
+)
+
+// retryBaseDelay and retryMaxDelay bound the exponential backoff between
+// retries of a failed batch. Declared as vars, rather than consts, so tests
+// can shrink them instead of waiting out real backoff delays.
+var (
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 30 * time.Second
 )
 
+// gcpLogger is the subset of *logging.Logger that ship uses, pulled out as
+// an interface so tests can inject a fake GCP backend that fails on demand.
+type gcpLogger interface {
+	Log(logging.Entry)
+	Flush() error
+}
+
+// AuditLogger ships audit entries to GCP Cloud Logging through a bounded
+// in-memory queue and a small worker pool, so a burst of entries batches
+// naturally instead of firing one Log+Flush call per entry. Batches that
+// keep failing are spilled to a dead-letter file instead of being dropped.
 type AuditLogger struct {
 	logEntries   <-chan map[string]interface{}
 	clusterName  string
 	projectID    string
-	googleLogger *logging.Logger
+	googleLogger gcpLogger
 	logger       *slog.Logger
+
+	queue          chan map[string]interface{}
+	deadLetterPath string
+	deadLetterMu   sync.Mutex
 }
 
-func NewAuditLogger(logEntries <-chan map[string]interface{}, clusterName, projectID string, googleLoggingClient *logging.Client, logger *slog.Logger) *AuditLogger {
+// NewAuditLogger returns an AuditLogger that reads from logEntries and
+// ships batches to GCP. queueSize bounds how many entries may be buffered
+// in memory while waiting to be shipped; once full, new entries are
+// spilled straight to the dead-letter file at deadLetterPath instead of
+// blocking the producer. deadLetterPath may be empty, in which case
+// entries that can't be delivered are logged and dropped.
+func NewAuditLogger(logEntries <-chan map[string]interface{}, clusterName, projectID string, googleLoggingClient *logging.Client, logger *slog.Logger, queueSize int, deadLetterPath string) *AuditLogger {
 	return &AuditLogger{
-		logEntries,
-		clusterName,
-		projectID,
-		googleLoggingClient.Logger("postgres-audit-log"),
-		logger.With(slog.Any("component", "auditLogger")),
+		logEntries:     logEntries,
+		clusterName:    clusterName,
+		projectID:      projectID,
+		googleLogger:   googleLoggingClient.Logger("postgres-audit-log"),
+		logger:         logger.With(slog.Any("component", "auditLogger")),
+		queue:          make(chan map[string]interface{}, queueSize),
+		deadLetterPath: deadLetterPath,
 	}
 }
 
+// Log reads entries from logEntries and ships them to GCP until ctx is
+// cancelled. It then gives in-flight workers up to drainGracePeriod to
+// finish shipping (or dead-lettering) whatever is still queued before
+// returning, so callers can wait on Log to know the queue has been drained.
 func (a *AuditLogger) Log(ctx context.Context) {
 	a.logger.Info("Starting audit logger")
+
+	var workers sync.WaitGroup
+	workers.Add(workerCount)
+	for i := 0; i < workerCount; i++ {
+		go func() {
+			defer workers.Done()
+			a.work()
+		}()
+	}
+
+	a.enqueue(ctx)
+	close(a.queue)
+
+	drained := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-time.After(drainGracePeriod):
+		a.logger.Warn("Timed out waiting for audit queue to drain, remaining entries may be lost", slog.Duration("grace_period", drainGracePeriod))
+	}
+
+	a.logger.Info("Audit logger stopped")
+}
+
+// enqueue forwards entries from logEntries onto the internal queue until
+// ctx is cancelled. A full queue spills straight to the dead-letter file
+// rather than blocking, so a slow GCP backend can't stall upstream
+// producers indefinitely.
+func (a *AuditLogger) enqueue(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
-			a.logger.Info("Context cancelled, stopping processing")
 			return
-		case logEntry := <-a.logEntries:
-			if err := a.sendToGCP(logEntry); err != nil {
-				a.logger.Error("Error sending audit log to GCP", slog.Any("error", err))
+		case entry := <-a.logEntries:
+			select {
+			case a.queue <- entry:
+			default:
+				a.logger.Warn("Audit queue full, writing entry straight to dead-letter file")
+				a.deadLetter(entry)
+			}
+		}
+	}
+}
+
+// work batches entries off the queue, flushing whenever a batch fills up
+// or batchInterval passes, until the queue is closed.
+func (a *AuditLogger) work() {
+	batch := make([]map[string]interface{}, 0, batchSize)
+	ticker := time.NewTicker(batchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case entry, ok := <-a.queue:
+			if !ok {
+				a.sendBatch(batch)
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= batchSize {
+				a.sendBatch(batch)
+				batch = nil
 			}
+		case <-ticker.C:
+			a.sendBatch(batch)
+			batch = nil
 		}
 	}
 }
 
-func (a *AuditLogger) sendToGCP(logEntry map[string]interface{}) error {
+// sendBatch ships batch to GCP, retrying with exponential backoff and
+// jitter on failure. If every attempt fails, the whole batch is spilled to
+// the dead-letter file instead of being dropped.
+func (a *AuditLogger) sendBatch(batch []map[string]interface{}) {
+	if len(batch) == 0 {
+		return
+	}
+
+	delay := retryBaseDelay
+	for attempt := 1; attempt <= maxSendAttempts; attempt++ {
+		if err := a.ship(batch); err == nil {
+			return
+		} else if attempt == maxSendAttempts {
+			a.logger.Error("Giving up on audit batch after repeated failures, writing to dead-letter file", slog.Int("batch_size", len(batch)), slog.Any("error", err))
+			break
+		} else {
+			a.logger.Warn("Failed to ship audit batch to GCP, retrying", slog.Int("attempt", attempt), slog.Any("error", err))
+		}
+
+		// Full jitter: sleep somewhere between 0 and the current backoff
+		// so retrying workers don't all hammer GCP in lockstep.
+		time.Sleep(time.Duration(rand.Int63n(int64(delay))))
+		delay *= 2
+		if delay > retryMaxDelay {
+			delay = retryMaxDelay
+		}
+	}
+
+	for _, entry := range batch {
+		a.deadLetter(entry)
+	}
+}
+
+// ship logs every entry in batch to GCP and flushes once for the batch as
+// a whole, to avoid a round trip per entry.
+func (a *AuditLogger) ship(batch []map[string]interface{}) error {
+	for _, logEntry := range batch {
+		entry, err := a.toLogEntry(logEntry)
+		if err != nil {
+			a.logger.Error("Dropping unshippable audit entry", slog.Any("error", err))
+			continue
+		}
+		a.googleLogger.Log(entry)
+	}
+
+	if err := a.googleLogger.Flush(); err != nil {
+		return fmt.Errorf("failed to flush logger: %w", err)
+	}
+
+	return nil
+}
+
+func (a *AuditLogger) toLogEntry(logEntry map[string]interface{}) (logging.Entry, error) {
 	entryJSON, err := json.Marshal(logEntry)
 	if err != nil {
-		return fmt.Errorf("failed to marshal log entry: %w", err)
+		return logging.Entry{}, fmt.Errorf("failed to marshal log entry: %w", err)
 	}
 
 	// Extract additional fields for labels
@@ -66,29 +239,30 @@ func (a *AuditLogger) sendToGCP(logEntry map[string]interface{}) error {
 		labels["databaseName"] = dbname
 	}
 
-	// Parse the AUDIT message to extract statement class
-	// Format: "AUDIT: SESSION,15,1,READ,SELECT,,,..."
-	// Fields: type, session_line, statement_id, class, command, ...
-	if message, ok := logEntry["message"].(string); ok {
-		// Split by comma after "AUDIT: "
-		auditPrefix := "AUDIT: "
-		if strings.HasPrefix(message, auditPrefix) {
-			auditData := strings.TrimPrefix(message, auditPrefix)
-			parts := strings.Split(auditData, ",")
-
-			// Extract audit type (SESSION, OBJECT, etc.) - index 0
-			if len(parts) > 0 && parts[0] != "" {
-				labels["auditType"] = parts[0]
+	// Parse the pgAudit CSV payload in the AUDIT message to extract
+	// statement class, command and object identity as labels.
+	if message, ok := logEntry["message"].(string); ok && pgaudit.HasPrefix(message) {
+		record, err := pgaudit.Parse(message)
+		if err != nil {
+			a.logger.Warn("Failed to parse pgAudit message", slog.Any("error", err))
+		} else {
+			if record.AuditType != "" {
+				labels["auditType"] = record.AuditType
 			}
-
-			// Extract statement class (READ, WRITE, etc.) - index 3
-			if len(parts) > 3 && parts[3] != "" {
-				labels["auditClass"] = parts[3]
+			if record.Class != "" {
+				labels["auditClass"] = record.Class
 			}
-
-			// Extract command (SELECT, INSERT, UPDATE, DELETE, etc.) - index 4
-			if len(parts) > 4 && parts[4] != "" {
-				labels["command"] = parts[4]
+			if record.Command != "" {
+				labels["command"] = record.Command
+			}
+			if record.ObjectType != "" {
+				labels["objectType"] = record.ObjectType
+			}
+			if record.ObjectName != "" {
+				labels["objectName"] = record.ObjectName
+			}
+			if record.Statement != "" {
+				labels["statement"] = record.Statement
 			}
 		}
 	}
@@ -109,19 +283,41 @@ func (a *AuditLogger) sendToGCP(logEntry map[string]interface{}) error {
 		},
 	}
 
-	entry := logging.Entry{
+	return logging.Entry{
 		Payload:  string(entryJSON),
 		Severity: logging.Info,
 		Labels:   labels,
 		Resource: resource,
+	}, nil
+}
+
+// deadLetter appends entry as a JSON line to the dead-letter file so an
+// operator can inspect and replay it later. If no dead-letter file is
+// configured, the entry is logged and dropped.
+func (a *AuditLogger) deadLetter(entry map[string]interface{}) {
+	if a.deadLetterPath == "" {
+		a.logger.Error("Dropping audit entry: no dead-letter file configured", slog.Any("entry", entry))
+		return
 	}
 
-	a.googleLogger.Log(entry)
+	data, err := json.Marshal(entry)
+	if err != nil {
+		a.logger.Error("Failed to marshal audit entry for dead-letter file", slog.Any("error", err))
+		return
+	}
+	data = append(data, '\n')
 
-	// Flush after every entry, to ensure it is sent right away to avoid losing entries in the event of crash or unexpected exit
-	if err = a.googleLogger.Flush(); err != nil {
-		return fmt.Errorf("failed to flush logger: %w", err)
+	a.deadLetterMu.Lock()
+	defer a.deadLetterMu.Unlock()
+
+	f, err := os.OpenFile(a.deadLetterPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		a.logger.Error("Failed to open dead-letter file", slog.Any("error", err))
+		return
 	}
+	defer f.Close()
 
-	return nil
+	if _, err := f.Write(data); err != nil {
+		a.logger.Error("Failed to write audit entry to dead-letter file", slog.Any("error", err))
+	}
 }
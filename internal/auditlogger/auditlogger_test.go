@@ -0,0 +1,228 @@
+package auditlogger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/logging"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestDeadLetterWritesJSONLines(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "dead-letter.jsonl")
+	a := &AuditLogger{logger: testLogger(), deadLetterPath: path}
+
+	a.deadLetter(map[string]interface{}{"message": "AUDIT: one"})
+	a.deadLetter(map[string]interface{}{"message": "AUDIT: two"})
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	lines := splitLines(t, data)
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(lines), data)
+	}
+	for i, want := range []string{"AUDIT: one", "AUDIT: two"} {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(lines[i]), &entry); err != nil {
+			t.Fatalf("line %d: %v", i, err)
+		}
+		if entry["message"] != want {
+			t.Errorf("line %d message = %v, want %q", i, entry["message"], want)
+		}
+	}
+}
+
+func TestDeadLetterWithoutPathDoesNotPanic(t *testing.T) {
+	a := &AuditLogger{logger: testLogger()}
+	a.deadLetter(map[string]interface{}{"message": "AUDIT: dropped"})
+}
+
+// TestEnqueueSpillsToDeadLetterWhenQueueFull simulates a GCP backend that
+// can't keep up: once the bounded queue is full, further entries must be
+// written straight to the dead-letter file rather than blocking the
+// producer or being silently dropped.
+func TestEnqueueSpillsToDeadLetterWhenQueueFull(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "dead-letter.jsonl")
+
+	logEntries := make(chan map[string]interface{})
+	a := &AuditLogger{
+		logger:         testLogger(),
+		logEntries:     logEntries,
+		queue:          make(chan map[string]interface{}, 1),
+		deadLetterPath: path,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		a.enqueue(ctx)
+		close(done)
+	}()
+
+	logEntries <- map[string]interface{}{"message": "AUDIT: queued"}
+	logEntries <- map[string]interface{}{"message": "AUDIT: overflow"}
+
+	// Give enqueue a moment to either slot the second entry into the
+	// (now-full) queue or spill it to the dead-letter file.
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	lines := splitLines(t, data)
+	if len(lines) != 1 {
+		t.Fatalf("got %d dead-lettered entries, want 1: %q", len(lines), data)
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["message"] != "AUDIT: overflow" {
+		t.Errorf("dead-lettered message = %v, want %q", entry["message"], "AUDIT: overflow")
+	}
+}
+
+// TestEnqueueStopsOnContextCancel ensures enqueue returns promptly once ctx
+// is cancelled instead of blocking forever waiting on logEntries, so Log's
+// shutdown sequence can't hang on it.
+func TestEnqueueStopsOnContextCancel(t *testing.T) {
+	logEntries := make(chan map[string]interface{})
+	a := &AuditLogger{
+		logger:     testLogger(),
+		logEntries: logEntries,
+		queue:      make(chan map[string]interface{}, 1),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		a.enqueue(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("enqueue did not return after ctx was cancelled")
+	}
+}
+
+// fakeGCPLogger is a gcpLogger that fails Flush the first failUntil times,
+// then succeeds, so tests can drive sendBatch's retry/backoff/dead-letter
+// transition without a real GCP backend.
+type fakeGCPLogger struct {
+	mu         sync.Mutex
+	failUntil  int
+	flushCalls int
+	logged     []logging.Entry
+}
+
+func (f *fakeGCPLogger) Log(e logging.Entry) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.logged = append(f.logged, e)
+}
+
+func (f *fakeGCPLogger) Flush() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.flushCalls++
+	if f.flushCalls <= f.failUntil {
+		return errors.New("simulated GCP flush failure")
+	}
+	return nil
+}
+
+// TestSendBatchRetriesThenSucceeds simulates a GCP backend that fails the
+// first two attempts before recovering: sendBatch must retry rather than
+// giving up, and the batch must never reach the dead-letter file once it
+// eventually succeeds.
+func TestSendBatchRetriesThenSucceeds(t *testing.T) {
+	retryBaseDelay, retryMaxDelay = time.Millisecond, 5*time.Millisecond
+	defer func() { retryBaseDelay, retryMaxDelay = 500*time.Millisecond, 30*time.Second }()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "dead-letter.jsonl")
+	fake := &fakeGCPLogger{failUntil: 2}
+	a := &AuditLogger{logger: testLogger(), googleLogger: fake, deadLetterPath: path}
+
+	a.sendBatch([]map[string]interface{}{{"message": "AUDIT: one"}})
+
+	if fake.flushCalls != 3 {
+		t.Errorf("Flush called %d times, want 3 (2 failures + 1 success)", fake.flushCalls)
+	}
+	// ship calls Log for every entry on every attempt, including ones that
+	// end up failing at Flush, so 3 attempts over a 1-entry batch means 3
+	// Log calls - not just the one that ultimately succeeded.
+	if len(fake.logged) != 3 {
+		t.Errorf("got %d entries logged to GCP, want 3 (one per attempt)", len(fake.logged))
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("dead-letter file exists after a batch that eventually succeeded")
+	}
+}
+
+// TestSendBatchDeadLettersAfterExhaustingRetries simulates a GCP backend
+// that never recovers: sendBatch must give up after maxSendAttempts and
+// spill the whole batch to the dead-letter file instead of losing it.
+func TestSendBatchDeadLettersAfterExhaustingRetries(t *testing.T) {
+	retryBaseDelay, retryMaxDelay = time.Millisecond, 5*time.Millisecond
+	defer func() { retryBaseDelay, retryMaxDelay = 500*time.Millisecond, 30*time.Second }()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "dead-letter.jsonl")
+	fake := &fakeGCPLogger{failUntil: maxSendAttempts}
+	a := &AuditLogger{logger: testLogger(), googleLogger: fake, deadLetterPath: path}
+
+	batch := []map[string]interface{}{
+		{"message": "AUDIT: one"},
+		{"message": "AUDIT: two"},
+	}
+	a.sendBatch(batch)
+
+	if fake.flushCalls != maxSendAttempts {
+		t.Errorf("Flush called %d times, want %d", fake.flushCalls, maxSendAttempts)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	lines := splitLines(t, data)
+	if len(lines) != len(batch) {
+		t.Fatalf("got %d dead-lettered entries, want %d: %q", len(lines), len(batch), data)
+	}
+}
+
+func splitLines(t *testing.T, data []byte) []string {
+	t.Helper()
+	s := strings.TrimSuffix(string(data), "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
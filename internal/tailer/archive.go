@@ -0,0 +1,183 @@
+package tailer
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// RotatedNameFunc returns the path of the generation'th archived predecessor
+// of filePath (1 = most recently rotated), or "" once generation exceeds
+// however many archives the caller's rotation scheme keeps. A candidate path
+// may exist either as-is or with a ".gz" suffix; both are checked.
+type RotatedNameFunc func(filePath string, generation int) string
+
+// DefaultRotatedNameFunc implements the numbered-suffix convention logrotate
+// uses by default: filePath+".1" is the most recently rotated file,
+// filePath+".2" the one before that, and so on.
+func DefaultRotatedNameFunc(filePath string, generation int) string {
+	if generation < 1 {
+		return ""
+	}
+	return fmt.Sprintf("%s.%d", filePath, generation)
+}
+
+// archivedGeneration is one rotated-away file t.rotatedNameFunc located on
+// disk, found either uncompressed or gzip-compressed.
+type archivedGeneration struct {
+	generation int
+	path       string
+	compressed bool
+}
+
+// findArchivedGenerations walks t.rotatedNameFunc starting at generation 1
+// until a candidate path is missing in both its plain and ".gz" forms,
+// returning every generation found, oldest last.
+func (t *Tailer) findArchivedGenerations() []archivedGeneration {
+	var found []archivedGeneration
+	for generation := 1; ; generation++ {
+		name := t.rotatedNameFunc(t.filePath, generation)
+		if name == "" {
+			break
+		}
+
+		if _, err := os.Stat(name); err == nil {
+			found = append(found, archivedGeneration{generation, name, false})
+			continue
+		}
+
+		gzName := name + ".gz"
+		if _, err := os.Stat(gzName); err == nil {
+			found = append(found, archivedGeneration{generation, gzName, true})
+			continue
+		}
+
+		break
+	}
+	return found
+}
+
+// resumeFromArchive looks for a rotated-away archive whose device/inode
+// still matches t.checkpointStore's saved state - true whenever the file the
+// checkpoint was taken against has since been renamed aside by log rotation
+// rather than compressed, since renaming preserves the inode. If one is
+// found, it replays every entry from the checkpointed offset onward: the
+// rest of that archive, then every newer archive in full, reporting true so
+// the caller knows the live file itself hasn't been read yet and should be
+// tailed from the beginning. A false return means there was nothing to
+// replay - either nothing has rotated since the checkpoint was saved, or
+// the matching archive has already been compressed and its original inode
+// is gone, in which case resuming precisely isn't possible and the caller
+// falls back to its normal start position.
+func (t *Tailer) resumeFromArchive(ctx context.Context, logFile *os.File) bool {
+	if t.rotatedNameFunc == nil || t.checkpointStore == nil {
+		return false
+	}
+
+	if _, ok, err := t.checkpointStore.Load(logFile); err != nil || ok {
+		// Either the checkpoint still matches the live file (no rotation
+		// happened since it was saved), or it's unreadable - either way
+		// there's nothing to replay.
+		return false
+	}
+
+	generations := t.findArchivedGenerations()
+
+	matchGeneration := -1
+	var matchOffset int64
+	for _, g := range generations {
+		if g.compressed {
+			continue
+		}
+
+		f, err := os.Open(g.path)
+		if err != nil {
+			continue
+		}
+		offset, ok, err := t.checkpointStore.Load(f)
+		f.Close()
+		if err == nil && ok {
+			matchGeneration = g.generation
+			matchOffset = offset
+			break
+		}
+	}
+
+	if matchGeneration == -1 {
+		return false
+	}
+
+	t.internalLogger.Info("Checkpoint matches a rotated archive, replaying forward",
+		slog.Int("generation", matchGeneration), slog.Int64("offset", matchOffset))
+
+	for i := len(generations) - 1; i >= 0; i-- {
+		g := generations[i]
+		if g.generation > matchGeneration {
+			continue
+		}
+
+		startOffset := int64(0)
+		if g.generation == matchGeneration {
+			startOffset = matchOffset
+		}
+		t.replayArchive(ctx, g, startOffset)
+	}
+
+	if err := t.checkpointStore.Reset(logFile); err != nil {
+		t.internalLogger.Warn("Failed to reset checkpoint after replaying rotated archives", slog.Any("error", err))
+	}
+
+	return true
+}
+
+// replayArchive decodes g in full starting at startOffset (ignored for
+// compressed archives, which can only be read from the beginning) and
+// dispatches every entry via t.processEntry.
+func (t *Tailer) replayArchive(ctx context.Context, g archivedGeneration, startOffset int64) {
+	f, err := os.Open(g.path)
+	if err != nil {
+		t.internalLogger.Warn("Failed to open rotated archive for replay", slog.String("path", g.path), slog.Any("error", err))
+		return
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if g.compressed {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.internalLogger.Warn("Failed to decompress rotated archive", slog.String("path", g.path), slog.Any("error", err))
+			return
+		}
+		defer gz.Close()
+		r = gz
+	} else if startOffset > 0 {
+		if _, err := f.Seek(startOffset, 0); err != nil {
+			t.internalLogger.Warn("Failed to seek into rotated archive", slog.String("path", g.path), slog.Any("error", err))
+			return
+		}
+	}
+
+	decoder := t.decoderFactory(r)
+	replayed := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		entry, err := decoder.Next()
+		if entry.Raw != "" {
+			t.processEntry(ctx, entry)
+			replayed++
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	t.internalLogger.Info("Replayed rotated archive", slog.String("path", g.path), slog.Int("entries", replayed))
+}
@@ -0,0 +1,54 @@
+package tailer
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultRotatedNameFunc(t *testing.T) {
+	if got := DefaultRotatedNameFunc("/var/log/app.log", 1); got != "/var/log/app.log.1" {
+		t.Errorf("DefaultRotatedNameFunc(generation 1) = %q, want %q", got, "/var/log/app.log.1")
+	}
+	if got := DefaultRotatedNameFunc("/var/log/app.log", 0); got != "" {
+		t.Errorf("DefaultRotatedNameFunc(generation 0) = %q, want empty", got)
+	}
+}
+
+func TestFindArchivedGenerations(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+
+	if err := os.WriteFile(logPath+".1", []byte("generation one\n"), 0o644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	gzFile, err := os.Create(logPath + ".2.gz")
+	if err != nil {
+		t.Fatalf("failed to create fixture: %v", err)
+	}
+	gzWriter := gzip.NewWriter(gzFile)
+	if _, err := gzWriter.Write([]byte("generation two\n")); err != nil {
+		t.Fatalf("failed to write gzip fixture: %v", err)
+	}
+	if err := gzWriter.Close(); err != nil {
+		t.Fatalf("failed to close gzip writer: %v", err)
+	}
+	if err := gzFile.Close(); err != nil {
+		t.Fatalf("failed to close gzip fixture: %v", err)
+	}
+
+	tailer := &Tailer{filePath: logPath, rotatedNameFunc: DefaultRotatedNameFunc}
+	generations := tailer.findArchivedGenerations()
+
+	if len(generations) != 2 {
+		t.Fatalf("findArchivedGenerations() returned %d generations, want 2", len(generations))
+	}
+	if generations[0].generation != 1 || generations[0].compressed {
+		t.Errorf("generations[0] = %+v, want generation 1, uncompressed", generations[0])
+	}
+	if generations[1].generation != 2 || !generations[1].compressed {
+		t.Errorf("generations[1] = %+v, want generation 2, compressed", generations[1])
+	}
+}
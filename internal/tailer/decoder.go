@@ -0,0 +1,237 @@
+package tailer
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Entry is a single record decoded from a tailed file: Fields holds
+// structured data when the decoder could parse the record (nil otherwise),
+// Raw is the record's text with any trailing newline stripped, and
+// ParseFailed is set when the decoder attempted to extract Fields but
+// couldn't, as opposed to decoders (or records) that never produce Fields
+// in the first place.
+type Entry struct {
+	Fields      map[string]interface{}
+	Raw         string
+	ParseFailed bool
+}
+
+// Decoder reads successive Entries from the reader it was built around.
+type Decoder interface {
+	// Next returns the next complete entry. It returns io.EOF when no more
+	// data is available right now - same contract as bufio.Reader.ReadString
+	// - so the Tailer can simply wait and retry rather than treat it as
+	// terminal.
+	Next() (Entry, error)
+	// Buffered reports how many bytes have been read from the underlying
+	// reader but not yet consumed into an Entry that Next has returned, so
+	// the Tailer can compute a checkpoint offset that doesn't skip data a
+	// decoder is still holding onto (e.g. a multi-line record in progress).
+	Buffered() int
+}
+
+// DecoderFactory builds a fresh Decoder reading from r. Tail calls this
+// once per (re)open of a file, so a decoder may keep state such as a
+// multi-line accumulator across calls within one file's lifetime, but must
+// not assume anything survives a rotation.
+type DecoderFactory func(r io.Reader) Decoder
+
+// AuditPredicate reports whether entry should be routed to the audit
+// channel rather than the plain log-line channel. NewTailer callers supply
+// one appropriate to the decoder they chose; NDJSONAuditPredicate matches
+// the pgAudit "AUDIT:" message convention the JSON decoder has always used.
+type AuditPredicate func(Entry) bool
+
+// NDJSONAuditPredicate is the AuditPredicate for NDJSONDecoder: it matches
+// entries whose "message" field starts with "AUDIT:".
+func NDJSONAuditPredicate(entry Entry) bool {
+	message, ok := entry.Fields["message"].(string)
+	return ok && strings.HasPrefix(message, "AUDIT:")
+}
+
+// lineDecoder is the shared implementation behind the single-line built-in
+// decoders: it reads one line at a time and hands each to parse to produce
+// that line's Fields.
+type lineDecoder struct {
+	reader  *bufio.Reader
+	parse   func(line string) (fields map[string]interface{}, ok bool)
+	pending string
+}
+
+func (d *lineDecoder) Next() (Entry, error) {
+	line, err := d.reader.ReadString('\n')
+	if err != nil {
+		// ReadString returns whatever it read before hitting err (typically
+		// io.EOF while tailing a file that hasn't finished writing this
+		// line yet), and those bytes are already gone from the reader's
+		// buffer - stash them so the next call picks up where this one left
+		// off instead of silently dropping the torn line.
+		d.pending += line
+		return Entry{}, err
+	}
+
+	raw := strings.TrimSuffix(strings.TrimSuffix(d.pending+line, "\n"), "\r")
+	d.pending = ""
+	entry := Entry{Raw: raw}
+	if d.parse != nil {
+		fields, ok := d.parse(raw)
+		entry.Fields = fields
+		entry.ParseFailed = !ok
+	}
+	return entry, nil
+}
+
+func (d *lineDecoder) Buffered() int {
+	return d.reader.Buffered() + len(d.pending)
+}
+
+// NDJSONDecoder is a DecoderFactory for newline-delimited JSON, the
+// Tailer's original behavior: each line is parsed as a JSON object, with
+// Fields left nil for lines that don't parse.
+func NDJSONDecoder() DecoderFactory {
+	return func(r io.Reader) Decoder {
+		return &lineDecoder{
+			reader: bufio.NewReader(r),
+			parse: func(line string) (map[string]interface{}, bool) {
+				var fields map[string]interface{}
+				if err := json.Unmarshal([]byte(line), &fields); err != nil {
+					return nil, false
+				}
+				return fields, true
+			},
+		}
+	}
+}
+
+// PlainTextDecoder is a DecoderFactory that treats every line as opaque
+// text: Fields is always nil, so NDJSONAuditPredicate never matches and,
+// with it, every entry is routed to the plain log-line channel.
+func PlainTextDecoder() DecoderFactory {
+	return func(r io.Reader) Decoder {
+		return &lineDecoder{reader: bufio.NewReader(r)}
+	}
+}
+
+// LogfmtDecoder is a DecoderFactory for logfmt-style lines
+// (key=value, key="quoted value"). Tokens without an "=" are ignored.
+func LogfmtDecoder() DecoderFactory {
+	return func(r io.Reader) Decoder {
+		return &lineDecoder{
+			reader: bufio.NewReader(r),
+			parse: func(line string) (map[string]interface{}, bool) {
+				return parseLogfmt(line), true
+			},
+		}
+	}
+}
+
+func parseLogfmt(line string) map[string]interface{} {
+	fields := make(map[string]interface{})
+	for _, token := range splitLogfmtTokens(line) {
+		key, value, ok := strings.Cut(token, "=")
+		if !ok || key == "" {
+			continue
+		}
+		fields[key] = strings.Trim(value, `"`)
+	}
+	return fields
+}
+
+// splitLogfmtTokens splits line on unquoted spaces, so a quoted value may
+// contain spaces without being split into multiple tokens.
+func splitLogfmtTokens(line string) []string {
+	var tokens []string
+	var token strings.Builder
+	inQuotes := false
+
+	for _, r := range line {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			token.WriteRune(r)
+		case r == ' ' && !inQuotes:
+			if token.Len() > 0 {
+				tokens = append(tokens, token.String())
+				token.Reset()
+			}
+		default:
+			token.WriteRune(r)
+		}
+	}
+	if token.Len() > 0 {
+		tokens = append(tokens, token.String())
+	}
+	return tokens
+}
+
+// recordStartPattern matches the start of a new record for
+// MultilineDecoder: an RFC3339-ish timestamp or a bracketed/plain log
+// level, the two conventions most Go and Java stack-trace-emitting
+// loggers use.
+var recordStartPattern = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}|\[?(DEBUG|INFO|WARN|WARNING|ERROR|FATAL|PANIC|TRACE)\b)`)
+
+// multilineDecoder joins continuation lines - anything not matching
+// recordStartPattern - onto the most recent record-starting line, so a
+// stack trace logged across many lines is delivered as a single Entry.
+// Because a record only ends when the next one begins, the final record in
+// a file is held back until another record starts; a decoder discarded
+// before then (e.g. replaced after a rotation) never emits it. A checkpoint
+// taken meanwhile does not skip it, since Buffered() still accounts for
+// those held-back bytes.
+type multilineDecoder struct {
+	reader  *bufio.Reader
+	pending []string
+	partial string
+}
+
+// MultilineDecoder is a DecoderFactory that accumulates continuation lines
+// until a new timestamp/level-prefixed line appears, joining them with "\n"
+// into a single Entry. Fields is always nil; classification should be done
+// on Raw by the caller's AuditPredicate if needed.
+func MultilineDecoder() DecoderFactory {
+	return func(r io.Reader) Decoder {
+		return &multilineDecoder{reader: bufio.NewReader(r)}
+	}
+}
+
+func (d *multilineDecoder) Next() (Entry, error) {
+	for {
+		line, err := d.reader.ReadString('\n')
+		if err != nil {
+			// As in lineDecoder, the partial bytes ReadString returns
+			// alongside err are already consumed from the buffer; hold
+			// onto them so the next call resumes the torn line instead of
+			// losing it.
+			d.partial += line
+			return Entry{}, err
+		}
+
+		raw := strings.TrimSuffix(strings.TrimSuffix(d.partial+line, "\n"), "\r")
+		d.partial = ""
+
+		if len(d.pending) == 0 {
+			d.pending = []string{raw}
+			continue
+		}
+
+		if recordStartPattern.MatchString(raw) {
+			entry := Entry{Raw: strings.Join(d.pending, "\n")}
+			d.pending = []string{raw}
+			return entry, nil
+		}
+
+		d.pending = append(d.pending, raw)
+	}
+}
+
+func (d *multilineDecoder) Buffered() int {
+	pendingLen := len(d.partial)
+	for _, line := range d.pending {
+		pendingLen += len(line) + 1 // +1 for the newline each line was read with
+	}
+	return d.reader.Buffered() + pendingLen
+}
@@ -0,0 +1,159 @@
+package tailer
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+// growingReader simulates a file being tailed while still being written:
+// each Read returns whatever has been appended so far, and an empty read
+// reports io.EOF rather than blocking, mirroring what bufio.Reader sees
+// when a line hasn't been newline-terminated yet.
+type growingReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *growingReader) Append(s string) {
+	r.data = append(r.data, s...)
+}
+
+func (r *growingReader) Read(p []byte) (int, error) {
+	if r.pos >= len(r.data) {
+		return 0, io.EOF
+	}
+	n := copy(p, r.data[r.pos:])
+	r.pos += n
+	return n, nil
+}
+
+func TestNDJSONDecoder(t *testing.T) {
+	r := strings.NewReader("{\"message\":\"AUDIT: hi\"}\nnot json\n")
+	decoder := NDJSONDecoder()(r)
+
+	entry, err := decoder.Next()
+	if err != nil {
+		t.Fatalf("Next() returned error: %v", err)
+	}
+	if entry.Fields["message"] != "AUDIT: hi" {
+		t.Errorf("Fields[message] = %v, want %q", entry.Fields["message"], "AUDIT: hi")
+	}
+	if !NDJSONAuditPredicate(entry) {
+		t.Error("NDJSONAuditPredicate() = false, want true for an AUDIT message")
+	}
+
+	entry, err = decoder.Next()
+	if err != nil {
+		t.Fatalf("Next() returned error: %v", err)
+	}
+	if entry.Fields != nil {
+		t.Errorf("Fields = %v, want nil for a line that isn't valid JSON", entry.Fields)
+	}
+	if !entry.ParseFailed {
+		t.Error("ParseFailed = false, want true for a line that isn't valid JSON")
+	}
+	if entry.Raw != "not json" {
+		t.Errorf("Raw = %q, want %q", entry.Raw, "not json")
+	}
+
+	if _, err := decoder.Next(); err != io.EOF {
+		t.Errorf("Next() error = %v, want io.EOF", err)
+	}
+}
+
+func TestPlainTextDecoder(t *testing.T) {
+	r := strings.NewReader("hello\nworld\n")
+	decoder := PlainTextDecoder()(r)
+
+	entry, err := decoder.Next()
+	if err != nil {
+		t.Fatalf("Next() returned error: %v", err)
+	}
+	if entry.Raw != "hello" || entry.Fields != nil {
+		t.Errorf("Next() = %+v, want Raw=hello, Fields=nil", entry)
+	}
+}
+
+func TestLogfmtDecoder(t *testing.T) {
+	r := strings.NewReader(`level=info msg="hello world" user=bob` + "\n")
+	decoder := LogfmtDecoder()(r)
+
+	entry, err := decoder.Next()
+	if err != nil {
+		t.Fatalf("Next() returned error: %v", err)
+	}
+	if entry.Fields["level"] != "info" {
+		t.Errorf("Fields[level] = %v, want info", entry.Fields["level"])
+	}
+	if entry.Fields["msg"] != "hello world" {
+		t.Errorf("Fields[msg] = %v, want %q", entry.Fields["msg"], "hello world")
+	}
+	if entry.Fields["user"] != "bob" {
+		t.Errorf("Fields[user] = %v, want bob", entry.Fields["user"])
+	}
+}
+
+func TestLineDecoderMidLineEOF(t *testing.T) {
+	r := &growingReader{}
+	r.Append(`{"message":"AUDIT: par`)
+	decoder := NDJSONDecoder()(r)
+
+	if _, err := decoder.Next(); err != io.EOF {
+		t.Fatalf("Next() error = %v, want io.EOF for an unterminated line", err)
+	}
+
+	r.Append(`tial"}` + "\n")
+	entry, err := decoder.Next()
+	if err != nil {
+		t.Fatalf("Next() returned error: %v", err)
+	}
+	if entry.Fields["message"] != "AUDIT: partial" {
+		t.Errorf("Fields[message] = %v, want %q - the line read before the mid-line EOF was lost", entry.Fields["message"], "AUDIT: partial")
+	}
+}
+
+func TestMultilineDecoder(t *testing.T) {
+	r := strings.NewReader(
+		"2026-01-01T00:00:00 ERROR something broke\n" +
+			"\tat foo.bar()\n" +
+			"\tat baz.qux()\n" +
+			"2026-01-01T00:00:01 INFO all good\n",
+	)
+	decoder := MultilineDecoder()(r)
+
+	entry, err := decoder.Next()
+	if err != nil {
+		t.Fatalf("Next() returned error: %v", err)
+	}
+	want := "2026-01-01T00:00:00 ERROR something broke\n\tat foo.bar()\n\tat baz.qux()"
+	if entry.Raw != want {
+		t.Errorf("Raw = %q, want %q", entry.Raw, want)
+	}
+
+	// The second record is only emitted once a further record starts, so
+	// it's still pending here.
+	if _, err := decoder.Next(); err != io.EOF {
+		t.Errorf("Next() error = %v, want io.EOF", err)
+	}
+}
+
+func TestMultilineDecoderMidLineEOF(t *testing.T) {
+	r := &growingReader{}
+	r.Append("2026-01-01T00:00:00 ERROR something br")
+	decoder := MultilineDecoder()(r)
+
+	if _, err := decoder.Next(); err != io.EOF {
+		t.Fatalf("Next() error = %v, want io.EOF for an unterminated line", err)
+	}
+
+	r.Append("oke\n2026-01-01T00:00:01 INFO all good\n")
+	entry, err := decoder.Next()
+	if err != nil {
+		t.Fatalf("Next() returned error: %v", err)
+	}
+	want := "2026-01-01T00:00:00 ERROR something broke"
+	if entry.Raw != want {
+		t.Errorf("Raw = %q, want %q - the line read before the mid-line EOF was lost", entry.Raw, want)
+	}
+}
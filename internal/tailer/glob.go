@@ -0,0 +1,131 @@
+package tailer
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ExpandPatterns expands one level of shell-style brace groups in each
+// pattern - "/var/log/app-{access,error}.log" becomes the two patterns
+// "/var/log/app-access.log" and "/var/log/app-error.log" - and returns
+// every resulting pattern. Patterns without a brace group pass through
+// unchanged.
+func ExpandPatterns(patterns []string) []string {
+	var expanded []string
+	for _, pattern := range patterns {
+		expanded = append(expanded, expandBraces(pattern)...)
+	}
+	return expanded
+}
+
+func expandBraces(pattern string) []string {
+	start := strings.IndexByte(pattern, '{')
+	if start == -1 {
+		return []string{pattern}
+	}
+	end := strings.IndexByte(pattern[start:], '}')
+	if end == -1 {
+		return []string{pattern}
+	}
+	end += start
+
+	prefix, group, suffix := pattern[:start], pattern[start+1:end], pattern[end+1:]
+
+	var expanded []string
+	for _, option := range strings.Split(group, ",") {
+		expanded = append(expanded, expandBraces(prefix+option+suffix)...)
+	}
+	return expanded
+}
+
+// GlobAll expands every brace group in patterns, globs each resulting
+// pattern - supporting filepath.Glob's usual wildcards plus a single "**"
+// path segment meaning "this directory and all its descendants" - and
+// returns the union of matches, deduplicated and sorted.
+func GlobAll(patterns []string) ([]string, error) {
+	seen := make(map[string]struct{})
+	var matches []string
+
+	for _, pattern := range ExpandPatterns(patterns) {
+		found, err := globOne(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range found {
+			if _, ok := seen[path]; ok {
+				continue
+			}
+			seen[path] = struct{}{}
+			matches = append(matches, path)
+		}
+	}
+
+	sort.Strings(matches)
+	return matches, nil
+}
+
+func globOne(pattern string) ([]string, error) {
+	if !strings.Contains(pattern, "**") {
+		return filepath.Glob(pattern)
+	}
+	return globRecursive(pattern)
+}
+
+// globRecursive handles a pattern containing exactly one "**" path segment:
+// "/var/log/**/*.log" matches *.log anywhere under /var/log, and
+// "/var/log/**" matches every file under /var/log.
+func globRecursive(pattern string) ([]string, error) {
+	root, suffix, ok := strings.Cut(pattern, "/**/")
+	if !ok {
+		trimmed, hasSuffix := strings.CutSuffix(pattern, "/**")
+		if !hasSuffix {
+			return nil, fmt.Errorf(`pattern %q: "**" must be its own path segment`, pattern)
+		}
+		root, suffix = trimmed, "*"
+	}
+
+	var matches []string
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		rel, relErr := filepath.Rel(root, path)
+		if relErr != nil {
+			return nil
+		}
+		// "**" stands for any number of leading directories, so try suffix
+		// against rel with zero, one, two, ... of them stripped off.
+		for {
+			if ok, _ := filepath.Match(suffix, rel); ok {
+				matches = append(matches, path)
+				return nil
+			}
+			_, after, found := strings.Cut(rel, string(filepath.Separator))
+			if !found {
+				return nil
+			}
+			rel = after
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// watchRootFor returns the directory fsnotify should watch, recursively, to
+// notice every file pattern could eventually match: everything up to its
+// first wildcard character.
+func watchRootFor(pattern string) string {
+	if idx := strings.IndexAny(pattern, "*?[{"); idx != -1 {
+		return filepath.Dir(pattern[:idx])
+	}
+	return filepath.Dir(pattern)
+}
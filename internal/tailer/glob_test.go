@@ -0,0 +1,55 @@
+package tailer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExpandPatterns(t *testing.T) {
+	got := ExpandPatterns([]string{"/var/log/app-{access,error}.log", "/var/log/plain.log"})
+	want := []string{"/var/log/app-access.log", "/var/log/app-error.log", "/var/log/plain.log"}
+
+	if len(got) != len(want) {
+		t.Fatalf("ExpandPatterns() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("ExpandPatterns() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestGlobAllRecursive(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "a", "b"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"top.log", filepath.Join("a", "mid.log"), filepath.Join("a", "b", "deep.log")} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	matches, err := GlobAll([]string{filepath.Join(dir, "**", "*.log")})
+	if err != nil {
+		t.Fatalf("GlobAll() error = %v", err)
+	}
+	if len(matches) != 3 {
+		t.Fatalf("GlobAll() = %v, want 3 matches", matches)
+	}
+}
+
+func TestWatchRootFor(t *testing.T) {
+	cases := map[string]string{
+		"/var/log/*.log":     "/var/log",
+		"/var/log/**/*.log":  "/var/log",
+		"/var/log/app.log":   "/var/log",
+		"/var/log/{a,b}.log": "/var/log",
+	}
+	for pattern, want := range cases {
+		if got := watchRootFor(pattern); got != want {
+			t.Errorf("watchRootFor(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package tailer
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics is a snapshot of the counters a Tailer accumulates over its
+// lifetime, named after the Prometheus convention for monotonic counters so
+// a caller can expose them under those names directly.
+type Metrics struct {
+	LinesReadTotal    int64
+	BytesReadTotal    int64
+	LinesDroppedTotal int64
+	RotationsTotal    int64
+	ParseErrorsTotal  int64
+}
+
+// tailerMetrics is the atomic storage behind a Tailer's Metrics snapshot.
+type tailerMetrics struct {
+	linesRead    int64
+	bytesRead    int64
+	linesDropped int64
+	rotations    int64
+	parseErrors  int64
+}
+
+// Metrics returns a snapshot of t's counters.
+func (t *Tailer) Metrics() Metrics {
+	return Metrics{
+		LinesReadTotal:    atomic.LoadInt64(&t.metrics.linesRead),
+		BytesReadTotal:    atomic.LoadInt64(&t.metrics.bytesRead),
+		LinesDroppedTotal: atomic.LoadInt64(&t.metrics.linesDropped),
+		RotationsTotal:    atomic.LoadInt64(&t.metrics.rotations),
+		ParseErrorsTotal:  atomic.LoadInt64(&t.metrics.parseErrors),
+	}
+}
+
+// LogMetricsPeriodically logs t.Metrics() every interval until ctx is done,
+// so an operator tailing several files (or just one) can see which one is
+// hot - reads/bytes processed, drops, rotations, parse errors - without
+// standing up a separate metrics endpoint.
+func LogMetricsPeriodically(ctx context.Context, t *Tailer, logger *slog.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m := t.Metrics()
+			logger.Info("Tailer metrics",
+				slog.Int64("lines_read_total", m.LinesReadTotal),
+				slog.Int64("bytes_read_total", m.BytesReadTotal),
+				slog.Int64("lines_dropped_total", m.LinesDroppedTotal),
+				slog.Int64("rotations_total", m.RotationsTotal),
+				slog.Int64("parse_errors_total", m.ParseErrorsTotal))
+		}
+	}
+}
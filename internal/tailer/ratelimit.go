@@ -0,0 +1,50 @@
+package tailer
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter is a leaky-bucket limiter bounding how many lines per second
+// a Tailer reads, so a single fast-writing file can't flood the downstream
+// channels at the expense of every other tailed file sharing them.
+type RateLimiter struct {
+	mu           sync.Mutex
+	tokens       float64
+	max          float64
+	refillPerSec float64
+	last         time.Time
+}
+
+// NewRateLimiter returns a RateLimiter allowing linesPerSecond lines per
+// second on average, with bursts of up to burst lines read instantly. The
+// bucket starts full, so a Tailer isn't throttled before it has even had a
+// chance to catch up on existing backlog.
+func NewRateLimiter(linesPerSecond float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		tokens:       float64(burst),
+		max:          float64(burst),
+		refillPerSec: linesPerSecond,
+		last:         time.Now(),
+	}
+}
+
+// Allow reports whether a line may be read now, consuming one token from
+// the bucket if so.
+func (r *RateLimiter) Allow() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.tokens += now.Sub(r.last).Seconds() * r.refillPerSec
+	if r.tokens > r.max {
+		r.tokens = r.max
+	}
+	r.last = now
+
+	if r.tokens < 1 {
+		return false
+	}
+	r.tokens--
+	return true
+}
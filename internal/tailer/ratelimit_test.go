@@ -0,0 +1,17 @@
+package tailer
+
+import "testing"
+
+func TestRateLimiterBurstThenThrottle(t *testing.T) {
+	limiter := NewRateLimiter(1, 2)
+
+	if !limiter.Allow() {
+		t.Fatal("Allow() = false, want true for the first token in the burst")
+	}
+	if !limiter.Allow() {
+		t.Fatal("Allow() = false, want true for the second token in the burst")
+	}
+	if limiter.Allow() {
+		t.Fatal("Allow() = true, want false once the burst is exhausted")
+	}
+}
@@ -0,0 +1,379 @@
+package tailer
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// ReadConfig controls which entries Tailer.ReadLogs returns. Tail, if
+// positive, limits the historical read to the last Tail lines (read
+// backwards in fixed-size blocks rather than loading the whole file);
+// zero or negative means read all available history. Since and Until, if
+// non-zero, filter entries against each entry's parsed JSON "timestamp"
+// field; entries whose timestamp can't be determined are always kept,
+// since filtering them out would be indistinguishable from data loss.
+// Follow keeps the returned LogWatcher open, streaming new entries
+// appended to the live file after the historical read completes.
+type ReadConfig struct {
+	Tail   int
+	Since  time.Time
+	Until  time.Time
+	Follow bool
+}
+
+// LogWatcher is returned by ReadLogs, analogous to Docker's
+// JSONFileLogger.ReadLogs: Msg delivers matching entries in chronological
+// order and is closed once the read completes (or, with Follow, once ctx
+// is done); Err delivers at most one fatal error and is never closed.
+type LogWatcher struct {
+	Msg chan Entry
+	Err chan error
+}
+
+// ReadLogs answers an on-demand query against t.filePath's history and,
+// optionally, its ongoing tail - the read-oriented counterpart to Tail's
+// continuous forwarding to logEntries/logLines. Unlike Tail, it doesn't
+// consult or update t.checkpointStore, and following the live file doesn't
+// handle rotation; a caller wanting both historical queries and
+// rotation-aware continuous forwarding should use Tail for the latter.
+func (t *Tailer) ReadLogs(ctx context.Context, cfg ReadConfig) *LogWatcher {
+	watcher := &LogWatcher{
+		Msg: make(chan Entry),
+		Err: make(chan error, 1),
+	}
+	go t.readLogs(ctx, cfg, watcher)
+	return watcher
+}
+
+func (t *Tailer) readLogs(ctx context.Context, cfg ReadConfig, watcher *LogWatcher) {
+	defer close(watcher.Msg)
+
+	if cfg.Tail > 0 {
+		t.emitTail(ctx, cfg, watcher)
+	} else {
+		t.emitFull(ctx, cfg, watcher)
+	}
+
+	select {
+	case <-ctx.Done():
+		return
+	default:
+	}
+
+	if cfg.Follow {
+		t.followLive(ctx, cfg, watcher)
+	}
+}
+
+// historicalSource is one file backing t.filePath's history.
+type historicalSource struct {
+	path       string
+	compressed bool
+}
+
+// historicalSources returns every readable file backing t.filePath's
+// history, oldest first: t.rotatedNameFunc's archives (if any), followed by
+// the live file itself.
+func (t *Tailer) historicalSources() []historicalSource {
+	var sources []historicalSource
+	if t.rotatedNameFunc != nil {
+		generations := t.findArchivedGenerations()
+		for i := len(generations) - 1; i >= 0; i-- {
+			g := generations[i]
+			sources = append(sources, historicalSource{g.path, g.compressed})
+		}
+	}
+	return append(sources, historicalSource{t.filePath, false})
+}
+
+// emitFull decodes every historical source in full, oldest first, sending
+// entries matching cfg's time range to watcher.Msg.
+func (t *Tailer) emitFull(ctx context.Context, cfg ReadConfig, watcher *LogWatcher) {
+	for _, src := range t.historicalSources() {
+		if !t.emitSource(ctx, src, cfg, watcher) {
+			return
+		}
+	}
+}
+
+// emitSource decodes src in full, sending matching entries to watcher.Msg.
+// It reports false if ctx was cancelled mid-read, so the caller can stop
+// moving on to later sources.
+func (t *Tailer) emitSource(ctx context.Context, src historicalSource, cfg ReadConfig, watcher *LogWatcher) bool {
+	f, err := os.Open(src.path)
+	if err != nil {
+		t.internalLogger.Warn("Failed to open log source for ReadLogs", slog.String("path", src.path), slog.Any("error", err))
+		return true
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if src.compressed {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.internalLogger.Warn("Failed to decompress log source for ReadLogs", slog.String("path", src.path), slog.Any("error", err))
+			return true
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	decoder := t.decoderFactory(r)
+	for {
+		entry, decodeErr := decoder.Next()
+		if entry.Raw != "" && matchesTimeRange(entry, cfg.Since, cfg.Until) {
+			select {
+			case <-ctx.Done():
+				return false
+			case watcher.Msg <- entry:
+			}
+		}
+		if decodeErr != nil {
+			return true
+		}
+	}
+}
+
+// emitTail reads the last cfg.Tail lines across t.historicalSources, newest
+// source first, and sends the ones matching cfg's time range to
+// watcher.Msg in chronological order.
+func (t *Tailer) emitTail(ctx context.Context, cfg ReadConfig, watcher *LogWatcher) {
+	for _, line := range t.tailLines(cfg.Tail) {
+		entry := t.decodeLine(line)
+		if !matchesTimeRange(entry, cfg.Since, cfg.Until) {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case watcher.Msg <- entry:
+		}
+	}
+}
+
+// tailLines returns up to the last n lines of t.historicalSources combined,
+// in chronological order, reading each source from its end and falling
+// back to older sources only once a newer one runs out of content.
+func (t *Tailer) tailLines(n int) []string {
+	sources := t.historicalSources()
+
+	var collected []string
+	remaining := n
+	for i := len(sources) - 1; i >= 0 && remaining > 0; i-- {
+		src := sources[i]
+
+		var lines []string
+		var err error
+		if src.compressed {
+			lines, err = gzipTailLines(src.path, remaining)
+		} else {
+			lines, err = reverseTailLines(src.path, remaining)
+		}
+		if err != nil {
+			t.internalLogger.Warn("Failed to read tail lines from log source", slog.String("path", src.path), slog.Any("error", err))
+			continue
+		}
+
+		collected = append(lines, collected...)
+		remaining -= len(lines)
+	}
+	return collected
+}
+
+// decodeLine runs line through a fresh instance of t.decoderFactory to
+// produce the Entry it would have decoded to in context, falling back to an
+// Entry with just Raw set if decoding it in isolation fails.
+func (t *Tailer) decodeLine(line string) Entry {
+	decoder := t.decoderFactory(strings.NewReader(line + "\n"))
+	entry, err := decoder.Next()
+	if err != nil {
+		return Entry{Raw: line}
+	}
+	return entry
+}
+
+// followLive tails t.filePath from its current end, streaming newly
+// appended entries matching cfg's time range to watcher.Msg until ctx is
+// done or (if cfg.Until is set) an entry's timestamp passes it. It does not
+// detect rotation; Tail is the rotation-aware, checkpoint-backed entry
+// point for continuous forwarding.
+func (t *Tailer) followLive(ctx context.Context, cfg ReadConfig, watcher *LogWatcher) {
+	logFile, err := os.Open(t.filePath)
+	if err != nil {
+		watcher.Err <- err
+		return
+	}
+	defer logFile.Close()
+
+	if _, err := logFile.Seek(0, io.SeekEnd); err != nil {
+		watcher.Err <- err
+		return
+	}
+
+	decoder := t.decoderFactory(logFile)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		entry, err := decoder.Next()
+		if err != nil {
+			time.Sleep(readInterval)
+			continue
+		}
+
+		if !cfg.Until.IsZero() {
+			if ts, ok := entryTimestamp(entry); ok && ts.After(cfg.Until) {
+				return
+			}
+		}
+		if !matchesTimeRange(entry, cfg.Since, cfg.Until) {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case watcher.Msg <- entry:
+		}
+	}
+}
+
+// matchesTimeRange reports whether entry falls within [since, until]
+// (either bound may be zero to mean unbounded). An entry whose timestamp
+// can't be determined always matches, rather than being silently dropped.
+func matchesTimeRange(entry Entry, since, until time.Time) bool {
+	if since.IsZero() && until.IsZero() {
+		return true
+	}
+	ts, ok := entryTimestamp(entry)
+	if !ok {
+		return true
+	}
+	if !since.IsZero() && ts.Before(since) {
+		return false
+	}
+	if !until.IsZero() && ts.After(until) {
+		return false
+	}
+	return true
+}
+
+// entryTimestamp extracts and parses entry's "timestamp" field, following
+// the same RFC3339Nano convention as the rest of this module (see
+// pkg/source/podlogs.splitTimestamp).
+func entryTimestamp(entry Entry) (time.Time, bool) {
+	raw, ok := entry.Fields["timestamp"]
+	if !ok {
+		return time.Time{}, false
+	}
+	s, ok := raw.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	ts, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return ts, true
+}
+
+// reverseTailLines returns up to the last n lines of the file at path,
+// reading fixed-size blocks from the end until more than n newlines have
+// been found (so the earliest of the n lines is known to be complete) and
+// splitting on "\n", rather than loading the whole file.
+func reverseTailLines(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	const blockSize = 64 * 1024
+	pos := info.Size()
+	var buf []byte
+
+	for pos > 0 && bytes.Count(buf, []byte("\n")) <= n {
+		readSize := int64(blockSize)
+		if readSize > pos {
+			readSize = pos
+		}
+		pos -= readSize
+
+		block := make([]byte, readSize)
+		if _, err := f.ReadAt(block, pos); err != nil {
+			return nil, err
+		}
+		buf = append(block, buf...)
+	}
+
+	text := strings.TrimSuffix(string(buf), "\n")
+	if text == "" {
+		return nil, nil
+	}
+	lines := strings.Split(text, "\n")
+
+	if pos > 0 {
+		// The unread block before pos may hold the rest of lines[0]'s line,
+		// so without it lines[0] is a partial line - drop it.
+		lines = lines[1:]
+	}
+
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, nil
+}
+
+// gzipTailLines returns up to the last n lines of the gzip-compressed file
+// at path. Unlike reverseTailLines, it decompresses the archive in full
+// rather than reading backwards, since gzip doesn't support seeking from
+// the end - acceptable here since a compressed rotated sibling is bounded
+// by whatever rotation policy produced it.
+func gzipTailLines(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 0, blockScanBufferSize), maxScanTokenSize)
+
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+const (
+	blockScanBufferSize = 64 * 1024
+	maxScanTokenSize    = 1024 * 1024
+)
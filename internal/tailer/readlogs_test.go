@@ -0,0 +1,96 @@
+package tailer
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testTailer(path string) *Tailer {
+	return &Tailer{
+		filePath:       path,
+		internalLogger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		decoderFactory: NDJSONDecoder(),
+	}
+}
+
+func writeLines(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	content := ""
+	for _, line := range lines {
+		content += line + "\n"
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func collect(t *testing.T, watcher *LogWatcher) []Entry {
+	t.Helper()
+	var entries []Entry
+	for entry := range watcher.Msg {
+		entries = append(entries, entry)
+	}
+	select {
+	case err := <-watcher.Err:
+		t.Fatalf("LogWatcher.Err = %v", err)
+	default:
+	}
+	return entries
+}
+
+func TestReadLogsTail(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	writeLines(t, path, `{"timestamp":"2024-01-01T00:00:00Z","message":"one"}`,
+		`{"timestamp":"2024-01-01T00:00:01Z","message":"two"}`,
+		`{"timestamp":"2024-01-01T00:00:02Z","message":"three"}`)
+
+	tailer := testTailer(path)
+
+	watcher := tailer.ReadLogs(context.Background(), ReadConfig{Tail: 2})
+	entries := collect(t, watcher)
+
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2: %+v", len(entries), entries)
+	}
+	if entries[0].Fields["message"] != "two" || entries[1].Fields["message"] != "three" {
+		t.Fatalf("got %+v, want last two lines in order", entries)
+	}
+}
+
+func TestReadLogsSinceUntil(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	writeLines(t, path, `{"timestamp":"2024-01-01T00:00:00Z","message":"one"}`,
+		`{"timestamp":"2024-01-01T00:00:01Z","message":"two"}`,
+		`{"timestamp":"2024-01-01T00:00:02Z","message":"three"}`)
+
+	tailer := testTailer(path)
+
+	since := time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC)
+	watcher := tailer.ReadLogs(context.Background(), ReadConfig{Since: since})
+	entries := collect(t, watcher)
+
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2: %+v", len(entries), entries)
+	}
+	if entries[0].Fields["message"] != "two" || entries[1].Fields["message"] != "three" {
+		t.Fatalf("got %+v, want entries at or after Since", entries)
+	}
+
+	until := time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC)
+	watcher = tailer.ReadLogs(context.Background(), ReadConfig{Until: until})
+	entries = collect(t, watcher)
+
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2: %+v", len(entries), entries)
+	}
+	if entries[0].Fields["message"] != "one" || entries[1].Fields["message"] != "two" {
+		t.Fatalf("got %+v, want entries at or before Until", entries)
+	}
+}
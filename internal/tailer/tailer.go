@@ -1,37 +1,106 @@
 package tailer
 
 import (
-	"bufio"
 	"context"
-	"encoding/json"
 	"io"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path"
 	"path/filepath"
-	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+
+	"github.com/nais/log-tailer/pkg/checkpoint"
 )
 
 const (
 	retryInterval        = 5 * time.Second
 	readInterval         = 100 * time.Millisecond
-	truncatedLength      = 200
 	newFileCheckInterval = 1 * time.Minute
+
+	// checkpointSaveInterval controls how often a Tailer fsyncs its current
+	// read offset to its checkpoint store, so StartAtCheckpoint can resume
+	// close to where a previous run left off instead of from scratch.
+	checkpointSaveInterval = 5 * time.Second
+
+	// rotationDrainGrace bounds how long Tail waits for a rotated-away
+	// file's remaining content to drain before giving up on it and moving
+	// on to the new file, in case the old descriptor never reaches EOF.
+	rotationDrainGrace = 10 * time.Second
+
+	// throttleWarnInterval bounds how often Tail logs a warning while a
+	// rate limiter is delaying reads, so sustained throttling doesn't spam
+	// the log once per readInterval.
+	throttleWarnInterval = 10 * time.Second
+)
+
+// StartPosition controls where a Tailer begins reading a file it has just
+// opened, mirroring nxadm/tail's Config.Location.
+type StartPosition int
+
+const (
+	// StartAtEnd seeks to the end of the file, so only lines written after
+	// the tailer starts are processed. This is the original behavior.
+	StartAtEnd StartPosition = iota
+	// StartAtBeginning reads the file from its first byte.
+	StartAtBeginning
+	// StartAtCheckpoint resumes from the offset last recorded in the
+	// Tailer's checkpoint store for this file, falling back to StartAtEnd
+	// if there is no usable checkpoint (e.g. first run, or a rotation).
+	StartAtCheckpoint
 )
 
 type Tailer struct {
-	filePath       string
-	logEntries     chan<- map[string]interface{}
-	logLines       chan<- string
-	internalLogger *slog.Logger
+	filePath        string
+	logEntries      chan<- map[string]interface{}
+	logLines        chan<- string
+	internalLogger  *slog.Logger
+	startPosition   StartPosition
+	checkpointStore *checkpoint.Store
+	decoderFactory  DecoderFactory
+	auditPredicate  AuditPredicate
+	rotatedNameFunc RotatedNameFunc
+	rateLimiter     *RateLimiter
+	metrics         tailerMetrics
 }
 
-func Watch(ctx context.Context, logFilePattern string, logEntries chan<- map[string]interface{}, logLines chan<- string, quit chan<- error, logger *slog.Logger) {
-	tailers := make(map[string]*Tailer)
-	err := lookForFiles(ctx, logFilePattern, logEntries, logLines, logger, tailers)
+// Watch discovers files matching any of logFilePatterns and tails each one
+// found, reconciling new matches as they appear via fsnotify (or a periodic
+// scan when fsnotify isn't available) and stopping a file's Tailer as soon
+// as fsnotify reports it removed or renamed away, so it doesn't linger
+// forever. Each pattern supports filepath.Glob's usual wildcards, a single
+// "**" path segment meaning "this directory and all its descendants", and
+// "{a,b}" brace groups - see GlobAll. startPosition and checkpointStoreFor
+// control where each discovered Tailer begins reading (checkpointStoreFor
+// may be nil, in which case StartAtCheckpoint behaves like StartAtEnd).
+// decoderFactory and auditPredicate control how each file's lines are
+// parsed and classified - pass NDJSONDecoder() and NDJSONAuditPredicate to
+// reproduce the Tailer's original JSON-per-line behavior. rotatedNameFunc, if
+// non-nil, lets a newly (re)started Tailer whose checkpoint no longer
+// matches its live file locate and replay rotated-away archives named after
+// that convention before resuming the live file - pass DefaultRotatedNameFunc
+// for logrotate's usual filePath+".N"[.gz] naming, or nil to disable this and
+// keep the original behavior of just resuming (or not) the live file.
+// rateLimiterFor, if non-nil, is called once per discovered file to build
+// the leaky-bucket limiter that paces its reads; return nil from it to leave
+// a particular file unthrottled. If metricsLogInterval is non-zero, each
+// discovered file's Metrics are logged on that interval for as long as it's
+// being tailed - see LogMetricsPeriodically.
+// Watch waits for every per-file Tailer it has spawned to return before
+// returning itself, once ctx is done, so a caller that treats Watch's return
+// as "safe to exit now" (as runMultiFileSource does) doesn't race an
+// in-flight Tailer's own checkpoint save - the same guarantee the
+// single-file path gets for free by calling Tail synchronously.
+func Watch(ctx context.Context, logFilePatterns []string, logEntries chan<- map[string]interface{}, logLines chan<- string, quit chan<- error, logger *slog.Logger, startPosition StartPosition, checkpointStoreFor func(filePath string) *checkpoint.Store, decoderFactory DecoderFactory, auditPredicate AuditPredicate, rotatedNameFunc RotatedNameFunc, rateLimiterFor func(filePath string) *RateLimiter, metricsLogInterval time.Duration) {
+	tailers := make(map[string]context.CancelFunc)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	err := lookForFiles(ctx, logFilePatterns, logEntries, logLines, logger, tailers, &wg, startPosition, checkpointStoreFor, decoderFactory, auditPredicate, rotatedNameFunc, rateLimiterFor, metricsLogInterval)
 	if err != nil {
 		quit <- err
 		return
@@ -50,7 +119,7 @@ func Watch(ctx context.Context, logFilePattern string, logEntries chan<- map[str
 				return
 			case <-newFileCheckTicker.C:
 				logger.Info("Ticker ticked")
-				err = lookForFiles(ctx, logFilePattern, logEntries, logLines, logger, tailers)
+				err = lookForFiles(ctx, logFilePatterns, logEntries, logLines, logger, tailers, &wg, startPosition, checkpointStoreFor, decoderFactory, auditPredicate, rotatedNameFunc, rateLimiterFor, metricsLogInterval)
 				if err != nil {
 					quit <- err
 					return
@@ -58,25 +127,36 @@ func Watch(ctx context.Context, logFilePattern string, logEntries chan<- map[str
 			}
 		}
 	} else {
-		dir := path.Dir(logFilePattern)
-		if err = watcher.Add(dir); err != nil {
-			logger.Error("Error creating watch for directory", slog.Any("error", err), slog.String("directory", dir))
-		}
 		defer watcher.Close()
 
+		for _, pattern := range ExpandPatterns(logFilePatterns) {
+			root := watchRootFor(pattern)
+			if err := addRecursiveWatches(watcher, root, logger); err != nil {
+				logger.Error("Error watching directory tree", slog.String("root", root), slog.Any("error", err))
+			}
+		}
+
 		for {
 			select {
 			case <-ctx.Done():
 				logger.Info("Context cancelled, stopping processing")
 				return
 			case event := <-watcher.Events:
-				if event.Has(fsnotify.Create) {
-					logger.Debug("Fsnotify sent event", slog.Any("event", event))
-					err = lookForFiles(ctx, logFilePattern, logEntries, logLines, logger, tailers)
+				logger.Debug("Fsnotify sent event", slog.Any("event", event))
+				switch {
+				case event.Has(fsnotify.Create):
+					if info, statErr := os.Stat(event.Name); statErr == nil && info.IsDir() {
+						if err := addRecursiveWatches(watcher, event.Name, logger); err != nil {
+							logger.Warn("Error watching new directory", slog.String("directory", event.Name), slog.Any("error", err))
+						}
+					}
+					err = lookForFiles(ctx, logFilePatterns, logEntries, logLines, logger, tailers, &wg, startPosition, checkpointStoreFor, decoderFactory, auditPredicate, rotatedNameFunc, rateLimiterFor, metricsLogInterval)
 					if err != nil {
 						quit <- err
 						return
 					}
+				case event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename):
+					stopTailer(tailers, event.Name, logger)
 				}
 			case err = <-watcher.Errors:
 				logger.Error("Error watching files", slog.Any("error", err))
@@ -87,9 +167,40 @@ func Watch(ctx context.Context, logFilePattern string, logEntries chan<- map[str
 	}
 }
 
-func lookForFiles(ctx context.Context, logFilePattern string, logEntries chan<- map[string]interface{}, logLines chan<- string, logger *slog.Logger, tailers map[string]*Tailer) error {
-	logger.Info("Looking for files matching pattern", slog.String("pattern", logFilePattern))
-	matches, err := filepath.Glob(logFilePattern)
+// addRecursiveWatches adds an fsnotify watch for root and every directory
+// beneath it, so a Create event is seen no matter how deep inside root a
+// tailed file lives or gets created.
+func addRecursiveWatches(watcher *fsnotify.Watcher, root string, logger *slog.Logger) error {
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			logger.Warn("Error walking directory tree", slog.String("path", path), slog.Any("error", err))
+			return nil
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if err := watcher.Add(path); err != nil {
+			logger.Warn("Error watching directory", slog.String("directory", path), slog.Any("error", err))
+		}
+		return nil
+	})
+}
+
+// stopTailer cancels filePath's Tailer, if one is running, and forgets it -
+// otherwise a removed or renamed-away file would linger in tailers forever.
+func stopTailer(tailers map[string]context.CancelFunc, filePath string, logger *slog.Logger) {
+	cancel, ok := tailers[filePath]
+	if !ok {
+		return
+	}
+	logger.Info("File removed or renamed away, stopping tail", slog.String("filepath", filePath))
+	cancel()
+	delete(tailers, filePath)
+}
+
+func lookForFiles(ctx context.Context, logFilePatterns []string, logEntries chan<- map[string]interface{}, logLines chan<- string, logger *slog.Logger, tailers map[string]context.CancelFunc, wg *sync.WaitGroup, startPosition StartPosition, checkpointStoreFor func(filePath string) *checkpoint.Store, decoderFactory DecoderFactory, auditPredicate AuditPredicate, rotatedNameFunc RotatedNameFunc, rateLimiterFor func(filePath string) *RateLimiter, metricsLogInterval time.Duration) error {
+	logger.Info("Looking for files matching patterns", slog.Any("patterns", logFilePatterns))
+	matches, err := GlobAll(logFilePatterns)
 	if err != nil {
 		logger.Error("Error listing files", slog.Any("error", err))
 		return err
@@ -97,20 +208,55 @@ func lookForFiles(ctx context.Context, logFilePattern string, logEntries chan<-
 	for _, match := range matches {
 		if _, ok := tailers[match]; !ok {
 			logger.Info("New file found, starting tail", slog.String("filepath", match))
-			t := NewTailer(match, logEntries, logLines, logger)
-			tailers[match] = t
-			go t.Tail(ctx)
+			var store *checkpoint.Store
+			if checkpointStoreFor != nil {
+				store = checkpointStoreFor(match)
+			}
+			var rateLimiter *RateLimiter
+			if rateLimiterFor != nil {
+				rateLimiter = rateLimiterFor(match)
+			}
+			t := NewTailer(match, logEntries, logLines, logger, startPosition, store, decoderFactory, auditPredicate, rotatedNameFunc, rateLimiter)
+			tailerCtx, cancel := context.WithCancel(ctx)
+			tailers[match] = cancel
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				t.Tail(tailerCtx)
+			}()
+			if metricsLogInterval > 0 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					LogMetricsPeriodically(tailerCtx, t, logger, metricsLogInterval)
+				}()
+			}
 		}
 	}
 	return nil
 }
 
-func NewTailer(filePath string, logEntries chan<- map[string]interface{}, logLines chan<- string, internalLogger *slog.Logger) *Tailer {
+// NewTailer returns a Tailer for filePath. startPosition controls where it
+// begins reading; checkpointStore, if non-nil, is kept up to date with the
+// read offset whatever startPosition is, and its saved offset is resumed
+// from when startPosition is StartAtCheckpoint. decoderFactory
+// builds the decoder used to parse each (re)opened file, and auditPredicate
+// decides which of its entries are routed to logEntries rather than logLines.
+// rotatedNameFunc, if non-nil, is used to locate and replay rotated-away
+// archives when the checkpoint no longer matches the live file - see Watch.
+// rateLimiter, if non-nil, paces how fast Tail reads this file.
+func NewTailer(filePath string, logEntries chan<- map[string]interface{}, logLines chan<- string, internalLogger *slog.Logger, startPosition StartPosition, checkpointStore *checkpoint.Store, decoderFactory DecoderFactory, auditPredicate AuditPredicate, rotatedNameFunc RotatedNameFunc, rateLimiter *RateLimiter) *Tailer {
 	return &Tailer{
-		filePath,
-		logEntries,
-		logLines,
-		internalLogger.With(slog.String("filename", path.Base(filePath))),
+		filePath:        filePath,
+		logEntries:      logEntries,
+		logLines:        logLines,
+		internalLogger:  internalLogger.With(slog.String("filename", path.Base(filePath))),
+		startPosition:   startPosition,
+		checkpointStore: checkpointStore,
+		decoderFactory:  decoderFactory,
+		auditPredicate:  auditPredicate,
+		rotatedNameFunc: rotatedNameFunc,
+		rateLimiter:     rateLimiter,
 	}
 }
 
@@ -128,26 +274,25 @@ func (t *Tailer) Tail(ctx context.Context) {
 	}
 	defer logFile.Close()
 
-	// Seek to end of file if it exists (don't reprocess old logs on restart)
 	// Track file info for rotation detection
 	var lastFileInfo os.FileInfo
 	if info, err := logFile.Stat(); err == nil {
 		lastFileInfo = info
-		if info.Size() > 0 {
-			if pos, err := logFile.Seek(0, 2); err != nil {
-				t.internalLogger.Warn("Failed to seek to end of file", slog.Any("error", err))
-			} else {
-				t.internalLogger.Info("Skipping existing log content - only new logs will be processed", slog.Int64("file_size_bytes", info.Size()), slog.Int64("position", pos))
-			}
-		} else {
-			t.internalLogger.Info("Log file is empty - waiting for new log entries")
+	}
+
+	if t.resumeFromArchive(ctx, logFile) {
+		// Every byte the checkpoint hadn't seen yet has just been replayed
+		// from the rotated archives that accumulated it, so the live file
+		// itself is entirely unread and should start from its first byte
+		// rather than wherever t.startPosition would otherwise put it.
+		if _, err := logFile.Seek(0, 0); err != nil {
+			t.internalLogger.Warn("Failed to seek to beginning of file after archive replay", slog.Any("error", err))
 		}
 	} else {
-		t.internalLogger.Warn("Failed to stat log file", slog.Any("error", err))
+		t.seekToStart(logFile)
 	}
 
-	// Use bufio.Reader for line-by-line reading with better tail support
-	reader := bufio.NewReader(logFile)
+	decoder := t.decoderFactory(logFile)
 
 	// Log the initial file position
 	pos, _ := logFile.Seek(0, 1)
@@ -157,23 +302,65 @@ func (t *Tailer) Tail(ctx context.Context) {
 	rotationCheckTicker := time.NewTicker(5 * time.Second)
 	defer rotationCheckTicker.Stop()
 
+	// Ticker to fsync the current read offset so StartAtCheckpoint can
+	// resume from close to here after a restart.
+	checkpointTicker := time.NewTicker(checkpointSaveInterval)
+	defer checkpointTicker.Stop()
+
 	entriesProcessed := 0
 
+	// Tracks how many reads rate limiting has delayed since the last
+	// throttle warning was logged, so repeated throttling doesn't spam the
+	// log once per readInterval.
+	throttledSinceWarn := 0
+	lastThrottleWarn := time.Now()
+
 	for {
 		// Check for context cancellation
 		select {
 		case <-ctx.Done():
 			t.internalLogger.Info("Context cancelled, stopping log processing")
+			t.saveCheckpoint(logFile, decoder)
 			return
 		default:
 		}
 
+		// Non-blocking checkpoint save
+		select {
+		case <-checkpointTicker.C:
+			t.saveCheckpoint(logFile, decoder)
+		default:
+		}
+
 		// Non-blocking rotation check
 		select {
 		case <-rotationCheckTicker.C:
-			if checkLogRotation(t.filePath, lastFileInfo) {
-				t.internalLogger.Info("Log rotation detected, reopening file...")
-				if err = logFile.Close(); err != nil {
+			switch detectRotation(t.filePath, lastFileInfo) {
+			case rotatedTruncated:
+				// Same inode, but shorter than before: the file was
+				// truncated in place rather than rotated away, so there is
+				// nothing to drain - just resume from the beginning.
+				t.internalLogger.Info("Log truncation detected, resuming from beginning of file")
+				if _, err := logFile.Seek(0, 0); err != nil {
+					t.internalLogger.Warn("Failed to seek to beginning of truncated file", slog.Any("error", err))
+				}
+				decoder = t.decoderFactory(logFile)
+
+				if t.checkpointStore != nil {
+					if err := t.checkpointStore.Reset(logFile); err != nil {
+						t.internalLogger.Warn("Failed to reset checkpoint after truncation", slog.Any("error", err))
+					}
+				}
+
+				if info, err := logFile.Stat(); err == nil {
+					lastFileInfo = info
+				}
+				atomic.AddInt64(&t.metrics.rotations, 1)
+			case rotatedAway:
+				t.internalLogger.Info("Log rotation detected, draining old file before switching over")
+				t.drainRemaining(ctx, decoder)
+
+				if err := logFile.Close(); err != nil {
 					t.internalLogger.Warn("Failed to close old log file", slog.Any("error", err))
 				}
 
@@ -186,20 +373,43 @@ func (t *Tailer) Tail(ctx context.Context) {
 				}
 
 				logFile = newFile
-				reader = bufio.NewReader(logFile)
+				decoder = t.decoderFactory(logFile)
+
+				if t.checkpointStore != nil {
+					if err := t.checkpointStore.Reset(logFile); err != nil {
+						t.internalLogger.Warn("Failed to reset checkpoint after rotation", slog.Any("error", err))
+					}
+				}
 
 				// Update file info
 				if info, err := logFile.Stat(); err == nil {
 					lastFileInfo = info
 					t.internalLogger.Info("Successfully reopened log file", slog.Int64("new_file_size_bytes", info.Size()))
 				}
+				atomic.AddInt64(&t.metrics.rotations, 1)
 			}
 		default:
 			// Don't block on rotation check
 		}
 
-		// Try to read the next line
-		line, err := reader.ReadString('\n')
+		// Apply backpressure if a rate limiter is configured and its bucket
+		// is empty, without skipping the rotation/checkpoint checks above on
+		// the next iteration.
+		if t.rateLimiter != nil && !t.rateLimiter.Allow() {
+			throttledSinceWarn++
+			if time.Since(lastThrottleWarn) >= throttleWarnInterval {
+				t.internalLogger.Warn("Rate limit is throttling reads",
+					slog.Int("delayed", throttledSinceWarn),
+					slog.Int64("dropped_total", atomic.LoadInt64(&t.metrics.linesDropped)))
+				throttledSinceWarn = 0
+				lastThrottleWarn = time.Now()
+			}
+			time.Sleep(readInterval)
+			continue
+		}
+
+		// Try to decode the next entry
+		entry, err := decoder.Next()
 		if err != nil {
 			if err == io.EOF {
 				// No more data available right now - wait and retry
@@ -208,28 +418,15 @@ func (t *Tailer) Tail(ctx context.Context) {
 			}
 
 			// Other error
-			t.internalLogger.Warn("Read error", slog.Any("error", err))
+			t.internalLogger.Warn("Decode error", slog.Any("error", err))
 			time.Sleep(readInterval)
 			continue
 		}
 
-		// Successfully read a line
-		line = strings.TrimSuffix(line, "\n")
-		line = strings.TrimSuffix(line, "\r") // Handle CRLF
-
-		if line == "" {
-			continue // Skip empty lines
-		}
-
-		// Parse JSON log entry
-		var logEntry map[string]interface{}
-		if err := json.Unmarshal([]byte(line), &logEntry); err != nil {
-			truncatedLine := line
-			if len(truncatedLine) > truncatedLength {
-				truncatedLine = truncatedLine[:truncatedLength]
-			}
-			t.internalLogger.Warn("Failed to parse JSON log line", slog.Any("error", err), slog.String("truncated_line", truncatedLine))
-			continue
+		atomic.AddInt64(&t.metrics.linesRead, 1)
+		atomic.AddInt64(&t.metrics.bytesRead, int64(len(entry.Raw)))
+		if entry.ParseFailed {
+			atomic.AddInt64(&t.metrics.parseErrors, 1)
 		}
 
 		entriesProcessed++
@@ -248,43 +445,161 @@ func (t *Tailer) Tail(ctx context.Context) {
 		default:
 		}
 
-		// Process the log entry
-		if message, ok := logEntry["message"].(string); ok && strings.HasPrefix(message, "AUDIT:") {
-			select {
-			case t.logEntries <- logEntry:
-			case <-ctx.Done():
+		t.processEntry(ctx, entry)
+	}
+}
+
+// processEntry dispatches entry to logEntries or logLines depending on
+// whether t.auditPredicate classifies it as a pgAudit message. Entries with
+// no raw content are skipped. An entry that's still undelivered when ctx is
+// cancelled is counted as dropped rather than blocking shutdown.
+func (t *Tailer) processEntry(ctx context.Context, entry Entry) {
+	if entry.Raw == "" {
+		return
+	}
+
+	if t.auditPredicate(entry) {
+		select {
+		case t.logEntries <- entry.Fields:
+		case <-ctx.Done():
+			atomic.AddInt64(&t.metrics.linesDropped, 1)
+		}
+	} else {
+		select {
+		case t.logLines <- entry.Raw:
+		case <-ctx.Done():
+			atomic.AddInt64(&t.metrics.linesDropped, 1)
+		}
+	}
+}
+
+// drainRemaining reads decoder to EOF, dispatching every complete entry, so
+// a rotated-away file's trailing content isn't lost before the Tailer
+// switches over to the new file. It gives up after rotationDrainGrace in
+// case the old descriptor never reaches a clean EOF.
+func (t *Tailer) drainRemaining(ctx context.Context, decoder Decoder) {
+	deadline := time.Now().Add(rotationDrainGrace)
+	drained := 0
+
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		entry, err := decoder.Next()
+		if entry.Raw != "" {
+			t.processEntry(ctx, entry)
+			drained++
+		}
+		if err != nil {
+			if err == io.EOF {
+				break
 			}
-		} else {
-			select {
-			case t.logLines <- line:
-			case <-ctx.Done():
+			t.internalLogger.Warn("Error draining rotated-away file", slog.Any("error", err))
+			break
+		}
+	}
+
+	t.internalLogger.Info("Drained trailing content from rotated-away file", slog.Int("entries", drained))
+}
+
+// seekToStart positions logFile according to t.startPosition: at the end
+// (StartAtEnd), at the first byte (StartAtBeginning), or at the offset
+// recorded in t.checkpointStore (StartAtCheckpoint), falling back to
+// StartAtEnd if there is no usable checkpoint for this file.
+func (t *Tailer) seekToStart(logFile *os.File) {
+	if t.startPosition == StartAtCheckpoint && t.checkpointStore != nil {
+		if offset, ok, err := t.checkpointStore.Load(logFile); err != nil {
+			t.internalLogger.Warn("Failed to load checkpoint", slog.Any("error", err))
+		} else if ok {
+			if pos, err := logFile.Seek(offset, 0); err != nil {
+				t.internalLogger.Warn("Failed to seek to checkpointed offset", slog.Int64("offset", offset), slog.Any("error", err))
+			} else {
+				t.internalLogger.Info("Resuming from checkpointed offset", slog.Int64("position", pos))
 			}
+			return
 		}
 	}
+
+	if t.startPosition == StartAtBeginning {
+		t.internalLogger.Info("Reading from beginning of file")
+		return
+	}
+
+	info, err := logFile.Stat()
+	if err != nil {
+		t.internalLogger.Warn("Failed to stat log file", slog.Any("error", err))
+		return
+	}
+	if info.Size() == 0 {
+		t.internalLogger.Info("Log file is empty - waiting for new log entries")
+		return
+	}
+	if pos, err := logFile.Seek(0, 2); err != nil {
+		t.internalLogger.Warn("Failed to seek to end of file", slog.Any("error", err))
+	} else {
+		t.internalLogger.Info("Skipping existing log content - only new logs will be processed", slog.Int64("file_size_bytes", info.Size()), slog.Int64("position", pos))
+	}
 }
 
-// checkLogRotation detects if the log file has been rotated
-// by comparing file stats (inode on Unix or size decrease)
-func checkLogRotation(filePath string, lastInfo os.FileInfo) bool {
+// saveCheckpoint records the file offset of the next byte the decoder
+// hasn't yet turned into an Entry, i.e. the underlying file descriptor's
+// position minus whatever the decoder has buffered or is still holding
+// onto (e.g. an in-progress multi-line record).
+func (t *Tailer) saveCheckpoint(logFile *os.File, decoder Decoder) {
+	if t.checkpointStore == nil {
+		return
+	}
+
+	pos, err := logFile.Seek(0, 1)
+	if err != nil {
+		t.internalLogger.Warn("Failed to determine current file offset", slog.Any("error", err))
+		return
+	}
+	offset := pos - int64(decoder.Buffered())
+
+	if err := t.checkpointStore.Save(logFile, offset); err != nil {
+		t.internalLogger.Warn("Failed to save checkpoint", slog.Any("error", err))
+	}
+}
+
+// rotationKind distinguishes the two ways a file can be "rotated" out from
+// under a Tailer, since they call for different recovery: a truncation
+// keeps the same inode and has nothing to drain, while a rename/replace
+// leaves the old inode (and any trailing content still in the Tailer's
+// buffer) to be drained before switching to the new file.
+type rotationKind int
+
+const (
+	notRotated rotationKind = iota
+	rotatedTruncated
+	rotatedAway
+)
+
+// detectRotation detects whether the log file has been rotated by
+// comparing file stats (inode on Unix, or size decrease).
+func detectRotation(filePath string, lastInfo os.FileInfo) rotationKind {
 	if lastInfo == nil {
-		return false
+		return notRotated
 	}
 
 	currentInfo, err := os.Stat(filePath)
 	if err != nil {
 		// File doesn't exist, might have been rotated and new one not created yet
-		return true
+		return rotatedAway
 	}
 
 	// Check if it's a different file (different inode on Unix systems)
 	if !os.SameFile(lastInfo, currentInfo) {
-		return true
+		return rotatedAway
 	}
 
-	// Check if file size decreased (indicates rotation/truncation)
+	// Check if file size decreased (indicates truncation in place)
 	if currentInfo.Size() < lastInfo.Size() {
-		return true
+		return rotatedTruncated
 	}
 
-	return false
+	return notRotated
 }
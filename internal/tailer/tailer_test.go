@@ -0,0 +1,121 @@
+package tailer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nais/log-tailer/pkg/checkpoint"
+)
+
+func testInternalLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func appendLine(t *testing.T, path, line string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func collectLines(t *testing.T, logLines <-chan string, n int) []string {
+	t.Helper()
+	var got []string
+	deadline := time.After(5 * time.Second)
+	for len(got) < n {
+		select {
+		case line := <-logLines:
+			got = append(got, line)
+		case <-deadline:
+			t.Fatalf("timed out after collecting %d/%d lines: %v", len(got), n, got)
+		}
+	}
+	return got
+}
+
+// TestTailResumesAcrossRestartWithoutDuplicationOrLoss simulates a process
+// crash and restart mid-file: a Tailer is cancelled partway through a
+// growing file, a fresh Tailer is then built against the same checkpoint
+// file, and together the two runs must deliver every line exactly once -
+// the guarantee request chunk0-1 asked for tests of.
+func TestTailResumesAcrossRestartWithoutDuplicationOrLoss(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	statePath := filepath.Join(dir, "app.state")
+
+	if err := os.WriteFile(logPath, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	logLines := make(chan string)
+	logEntries := make(chan map[string]interface{})
+
+	// StartAtBeginning for the first run, rather than StartAtCheckpoint,
+	// sidesteps a race against Tail's own goroutine startup: whatever has
+	// been appended by the time Tail opens the file is read from byte 0
+	// regardless of exactly when that happens, whereas StartAtCheckpoint
+	// with no checkpoint yet falls back to StartAtEnd and would skip lines
+	// written before Tail got scheduled. checkpointStore is independent of
+	// startPosition, so this run still saves a checkpoint on cancellation.
+	store1 := checkpoint.NewStore(statePath, 0)
+	t1 := NewTailer(logPath, logEntries, logLines, testInternalLogger(), StartAtBeginning, store1, NDJSONDecoder(), NDJSONAuditPredicate, nil, nil)
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	tail1Done := make(chan struct{})
+	go func() {
+		t1.Tail(ctx1)
+		close(tail1Done)
+	}()
+
+	for i := 0; i < 5; i++ {
+		appendLine(t, logPath, fmt.Sprintf(`{"message":"line %d"}`, i))
+	}
+	firstBatch := collectLines(t, logLines, 5)
+
+	cancel1()
+	select {
+	case <-tail1Done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("first Tail did not return after ctx was cancelled")
+	}
+
+	store2 := checkpoint.NewStore(statePath, 0)
+	t2 := NewTailer(logPath, logEntries, logLines, testInternalLogger(), StartAtCheckpoint, store2, NDJSONDecoder(), NDJSONAuditPredicate, nil, nil)
+
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	go t2.Tail(ctx2)
+
+	for i := 5; i < 10; i++ {
+		appendLine(t, logPath, fmt.Sprintf(`{"message":"line %d"}`, i))
+	}
+	secondBatch := collectLines(t, logLines, 5)
+
+	all := append(firstBatch, secondBatch...)
+	if len(all) != 10 {
+		t.Fatalf("got %d total lines, want 10: %v", len(all), all)
+	}
+
+	seen := make(map[string]bool)
+	for i, line := range all {
+		want := fmt.Sprintf(`{"message":"line %d"}`, i)
+		if line != want {
+			t.Errorf("line %d = %q, want %q - entries were lost, duplicated, or reordered across the restart", i, line, want)
+		}
+		if seen[line] {
+			t.Errorf("line %q delivered more than once", line)
+		}
+		seen[line] = true
+	}
+}
@@ -1,49 +1,84 @@
 package main
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
-	"io"
 	"log"
+	"log/slog"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"cloud.google.com/go/logging"
-	mrpb "google.golang.org/genproto/googleapis/api/monitoredres"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+
+	"github.com/nais/log-tailer/internal/auditlogger"
+	"github.com/nais/log-tailer/internal/filelogger"
+	"github.com/nais/log-tailer/internal/tailer"
+	"github.com/nais/log-tailer/pkg/checkpoint"
+	"github.com/nais/log-tailer/pkg/source/podlogs"
 )
 
+
 func main() {
 	logFilePath := flag.String("log-file", "", "Path to the log file to tail (required)")
 	projectID := flag.String("project-id", "", "GCP project ID (optional, for local testing)")
 	fromBeginning := flag.Bool("from-beginning", false, "Read from the beginning of the file instead of tailing (for testing)")
 	testLastN := flag.Int("test-last-n", 0, "Test mode: read last N entries from file and exit (for debugging)")
+	stateFilePath := flag.String("state-file", "", "Path to a state file used to checkpoint the read offset across restarts (optional, single-file mode only)")
+	stateDir := flag.String("state-dir", "", "Directory used to checkpoint each file's read offset when -log-file is a glob or directory (optional)")
+	ignoreOlder := flag.Duration("ignore-older", 0, "Ignore a checkpointed offset if the log file's mtime is older than this duration (0 disables the check)")
+	source := flag.String("source", "file", `Log source to use: "file" to tail a local log file, or "pods" to stream Postgres pod logs directly via the Kubernetes API`)
+	podLabelSelector := flag.String("pod-label-selector", "", "Label selector for pods to stream logs from when -source=pods (defaults to cluster-name=<cluster name>)")
+	auditQueueSize := flag.Int("audit-queue-size", 1000, "Maximum number of audit entries buffered in memory while being shipped to GCP")
+	deadLetterFile := flag.String("dead-letter-file", "", "Path to a file where audit entries are appended as JSON lines after repeated GCP shipping failures (optional)")
+	rateLimitLinesPerSec := flag.Float64("rate-limit-lines-per-sec", 0, "Maximum average lines per second read from each tailed file (0 disables rate limiting)")
+	rateLimitBurst := flag.Int("rate-limit-burst", 100, "Burst size for -rate-limit-lines-per-sec")
+	metricsLogInterval := flag.Duration("metrics-log-interval", 0, "How often to log each tailed file's read/drop/rotation counters (0 disables)")
+	decoderName := flag.String("decoder", "ndjson", `Line decoder to use: "ndjson" (default), "plain", "logfmt", or "multiline"`)
 
 	flag.Parse()
 
-	if *logFilePath == "" {
+	decoderFactory, err := decoderFactoryFor(*decoderName)
+	if err != nil {
 		flag.Usage()
-		log.Fatal("Flag -log-file is required")
+		log.Fatal(err)
 	}
 
-	logFile, err := os.Open(*logFilePath)
-	if err != nil {
-		log.Fatalf("Failed to open log file: %v", err)
+	if *source != "file" && *source != "pods" {
+		flag.Usage()
+		log.Fatalf("Invalid -source %q: must be \"file\" or \"pods\"", *source)
 	}
-	defer logFile.Close()
 
-	// Test mode: read last N entries and exit
-	if *testLastN > 0 {
-		testReadLastEntries(logFile, *testLastN)
-		return
+	if *source == "file" && *logFilePath == "" {
+		flag.Usage()
+		log.Fatal("Flag -log-file is required when -source=file")
+	}
+
+	multiFile := *source == "file" && isMultiFilePattern(*logFilePath)
+
+	if *source == "file" && !multiFile {
+		logFile, err := os.Open(*logFilePath)
+		if err != nil {
+			log.Fatalf("Failed to open log file: %v", err)
+		}
+		logFile.Close()
+
+		// Test mode: read last N entries and exit
+		if *testLastN > 0 {
+			testReadLastEntries(*logFilePath, *testLastN, decoderFactory)
+			return
+		}
+	} else if *testLastN > 0 {
+		log.Fatal("-test-last-n is only supported when -log-file names a single file")
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -86,255 +121,218 @@ func main() {
 	}
 	defer client.Close()
 
-	// Seek to end of file if it exists (don't reprocess old logs on restart)
-	if !*fromBeginning {
-		if info, err := logFile.Stat(); err == nil {
-			if info.Size() > 0 {
-				if pos, err := logFile.Seek(0, 2); err != nil {
-					log.Printf("Warning: Failed to seek to end of file: %v", err)
-				} else {
-					log.Printf("Skipping existing log content (file size: %d bytes, position: %d) - only new logs will be processed", info.Size(), pos)
-					// Note: We're now at the end of the file, which might be in the middle of a line
-					// The scanner will wait for the next complete line (ending with \n)
-					// This is correct behavior - we'll catch the next complete log entry
-				}
-			} else {
-				log.Printf("Log file is empty - waiting for new log entries")
-			}
-		} else {
-			log.Printf("Warning: Failed to stat log file: %v", err)
+	if *source == "pods" {
+		labelSelector := *podLabelSelector
+		if labelSelector == "" {
+			labelSelector = fmt.Sprintf("cluster-name=%s", clusterName)
 		}
-	} else {
-		log.Printf("Reading from beginning of file (--from-beginning flag set)")
+		runPodsSource(ctx, k8sClient, client, namespace, clusterName, teamProjectID, labelSelector, *auditQueueSize, *deadLetterFile)
+		return
 	}
 
-	// Track file info for rotation detection
-	var lastFileInfo os.FileInfo
-	if info, err := logFile.Stat(); err == nil {
-		lastFileInfo = info
+	if multiFile {
+		runMultiFileSource(ctx, *logFilePath, *stateDir, *ignoreOlder, *fromBeginning, client, clusterName, teamProjectID, *auditQueueSize, *deadLetterFile, *rateLimitLinesPerSec, *rateLimitBurst, *metricsLogInterval, decoderFactory)
+		return
 	}
 
-	// Use bufio.Scanner for efficient line-by-line reading
-	scanner := bufio.NewScanner(logFile)
-
-	// Increase buffer size to handle large log lines (default is 64KB)
-	const maxScanTokenSize = 1024 * 1024 // 1MB
-	buf := make([]byte, maxScanTokenSize)
-	scanner.Buffer(buf, maxScanTokenSize)
+	runFileSource(ctx, *logFilePath, *fromBeginning, *stateFilePath, *ignoreOlder, client, clusterName, teamProjectID, *auditQueueSize, *deadLetterFile, *rateLimitLinesPerSec, *rateLimitBurst, *metricsLogInterval, decoderFactory)
+}
 
-	log.Println("Starting log tail...")
+// decoderFactoryFor resolves the -decoder flag's value to the tailer
+// DecoderFactory it names.
+func decoderFactoryFor(name string) (tailer.DecoderFactory, error) {
+	switch name {
+	case "ndjson":
+		return tailer.NDJSONDecoder(), nil
+	case "plain":
+		return tailer.PlainTextDecoder(), nil
+	case "logfmt":
+		return tailer.LogfmtDecoder(), nil
+	case "multiline":
+		return tailer.MultilineDecoder(), nil
+	default:
+		return nil, fmt.Errorf(`invalid -decoder %q: must be "ndjson", "plain", "logfmt", or "multiline"`, name)
+	}
+}
 
-	// Log the initial file position
-	if pos, err := logFile.Seek(0, 1); err == nil {
-		log.Printf("Starting at file position: %d", pos)
+// isMultiFilePattern reports whether logFilePath should be tailed as a set
+// of files rather than a single file: a glob pattern, or a plain directory.
+func isMultiFilePattern(logFilePath string) bool {
+	if strings.ContainsAny(logFilePath, "*?[{") {
+		return true
+	}
+	if info, err := os.Stat(logFilePath); err == nil && info.IsDir() {
+		return true
 	}
+	return false
+}
 
-	// Track activity for debugging
-	lastStatusLog := time.Now()
-	scanAttempts := 0
+// runFileSource tails logFilePath via internal/tailer, parses pgAudit JSON
+// log lines, ships AUDIT entries to GCP and prints everything else to
+// stdout, checkpointing the read offset so it can resume across restarts.
+// rateLimitLinesPerSec/rateLimitBurst pace the tail (0 disables rate
+// limiting); metricsLogInterval, if non-zero, periodically logs the
+// Tailer's read/drop/rotation counters. decoderFactory selects how lines
+// are parsed - see decoderFactoryFor.
+func runFileSource(ctx context.Context, logFilePath string, fromBeginning bool, stateFilePath string, ignoreOlder time.Duration, client *logging.Client, clusterName, teamProjectID string, auditQueueSize int, deadLetterPath string, rateLimitLinesPerSec float64, rateLimitBurst int, metricsLogInterval time.Duration, decoderFactory tailer.DecoderFactory) {
+	logger := slog.Default()
 
-	// Ticker to check for log rotation every 5 seconds
-	rotationCheckTicker := time.NewTicker(5 * time.Second)
-	defer rotationCheckTicker.Stop()
+	logEntries := make(chan map[string]interface{})
+	logLines := make(chan string)
 
-	entriesProcessed := 0
+	var loggers sync.WaitGroup
+	loggers.Add(2)
 
-	for {
-		// Check for context cancellation
-		select {
-		case <-ctx.Done():
-			log.Println("Context cancelled, stopping log processing")
-			return
-		default:
-		}
+	auditLogger := auditlogger.NewAuditLogger(logEntries, clusterName, teamProjectID, client, logger, auditQueueSize, deadLetterPath)
+	go func() {
+		defer loggers.Done()
+		auditLogger.Log(ctx)
+	}()
 
-		// Non-blocking rotation check
-		select {
-		case <-rotationCheckTicker.C:
-			if rotated, err := checkLogRotation(*logFilePath, lastFileInfo); err != nil {
-				log.Printf("Error checking log rotation: %v", err)
-			} else if rotated {
-				log.Println("Log rotation detected, reopening file...")
-				if err := logFile.Close(); err != nil {
-					log.Printf("Warning: Failed to close old log file: %v", err)
-				}
-
-				// Reopen the file
-				newFile, err := os.Open(*logFilePath)
-				if err != nil {
-					log.Printf("Failed to reopen log file after rotation: %v", err)
-					time.Sleep(time.Second)
-					continue
-				}
-
-				logFile = newFile
-				scanner = bufio.NewScanner(logFile)
-
-				// Update file info
-				if info, err := logFile.Stat(); err == nil {
-					lastFileInfo = info
-					log.Printf("Successfully reopened log file (new size: %d bytes)", info.Size())
-				}
-			}
-		default:
-			// Don't block on rotation check
-		}
+	fileLogger := filelogger.NewFileLogger(logLines, logger, false)
+	go func() {
+		defer loggers.Done()
+		fileLogger.Log(ctx)
+	}()
 
-		// Try to scan the next line
-		if scanner.Scan() {
-			line := scanner.Text()
-			scanAttempts = 0 // Reset counter on successful scan
-
-			// Parse JSON log entry
-			var logEntry map[string]interface{}
-			if err := json.Unmarshal([]byte(line), &logEntry); err != nil {
-				log.Printf("Failed to parse JSON log line: %v", err)
-				continue
-			}
-
-			entriesProcessed++
-			if entriesProcessed == 1 {
-				log.Printf("Successfully read first log entry!")
-			}
-			if entriesProcessed%100 == 0 {
-				log.Printf("Processed %d log entries", entriesProcessed)
-			}
-
-			// Check for context cancellation between processing entries
-			select {
-			case <-ctx.Done():
-				log.Println("Context cancelled, stopping log processing")
-				return
-			default:
-			}
-
-			// Process the log entry
-			if message, ok := logEntry["message"].(string); ok && strings.HasPrefix(message, "AUDIT:") {
-				// Send to GCP in background to avoid blocking
-				go func(entry map[string]interface{}) {
-					if err := sendToGCP(client, entry, clusterName, teamProjectID); err != nil {
-						log.Printf("Failed to send audit log: %v", err)
-					}
-				}(logEntry)
-			} else {
-				// Non-audit logs printed to stdout
-				if jsonOutput, err := json.Marshal(logEntry); err == nil {
-					fmt.Println(string(jsonOutput))
-				}
-			}
-		} else {
-			// No more lines available - check for errors
-			if err := scanner.Err(); err != nil {
-				log.Printf("Scanner error: %v", err)
-				time.Sleep(100 * time.Millisecond)
-				continue
-			}
-
-			// EOF reached, wait for new data (tail behavior)
-			scanAttempts++
-
-			// Log status every 10 seconds when we're waiting
-			if time.Since(lastStatusLog) > 10*time.Second {
-				if info, err := logFile.Stat(); err == nil {
-					pos, _ := logFile.Seek(0, 1)
-					remaining := info.Size() - pos
-					log.Printf("Waiting for new data: file size=%d, position=%d, remaining=%d bytes, scan attempts=%d, entries=%d",
-						info.Size(), pos, remaining, scanAttempts, entriesProcessed)
-
-					// If there's remaining data but we're not reading it, that's a problem
-					if remaining > 0 {
-						log.Printf("WARNING: File has %d bytes remaining but scanner returned false - possible partial line issue", remaining)
-					}
-				}
-				lastStatusLog = time.Now()
-			}
-
-			time.Sleep(100 * time.Millisecond)
-		}
+	// Wait for the audit/file loggers to drain their queues before this
+	// function - and therefore main - returns, so a shutdown doesn't cut
+	// off in-flight audit entries.
+	defer loggers.Wait()
+
+	startPosition := tailer.StartAtCheckpoint
+	if fromBeginning {
+		startPosition = tailer.StartAtBeginning
 	}
-}
+	checkpointStore := checkpoint.NewStore(stateFilePath, ignoreOlder)
 
-func sendToGCP(client *logging.Client, logEntry map[string]interface{}, clusterName, projectID string) error {
-	entryJSON, err := json.Marshal(logEntry)
-	if err != nil {
-		return fmt.Errorf("failed to marshal log entry: %w", err)
+	var rateLimiter *tailer.RateLimiter
+	if rateLimitLinesPerSec > 0 {
+		rateLimiter = tailer.NewRateLimiter(rateLimitLinesPerSec, rateLimitBurst)
 	}
 
-	logger := client.Logger("postgres-audit-log")
+	log.Println("Starting log tail...")
+	t := tailer.NewTailer(logFilePath, logEntries, logLines, logger, startPosition, checkpointStore, decoderFactory, tailer.NDJSONAuditPredicate, tailer.DefaultRotatedNameFunc, rateLimiter)
 
-	// Extract additional fields for labels
-	labels := make(map[string]string)
+	if metricsLogInterval > 0 {
+		loggers.Add(1)
+		go func() {
+			defer loggers.Done()
+			tailer.LogMetricsPeriodically(ctx, t, logger, metricsLogInterval)
+		}()
+	}
 
-	// Add cluster name as database_id
-	labels["databaseId"] = fmt.Sprintf("%s:%s", projectID, clusterName)
+	t.Tail(ctx)
+}
 
-	// Extract user from root level
-	if user, ok := logEntry["user"].(string); ok && user != "" {
-		labels["user"] = user
+// runMultiFileSource tails every file matching logFilePattern (a glob or a
+// directory) using internal/tailer's multi-file watcher, instead of the
+// single-file Tail loop runFileSource uses. Each matched file gets its own
+// tailer goroutine, but they all feed the same logEntries/logLines channels
+// consumed by the audit/file loggers below, so AUDIT entries from any file
+// are shipped to GCP and everything else is printed exactly as the
+// single-file source does. rateLimitLinesPerSec/rateLimitBurst pace each
+// file independently (0 disables rate limiting); metricsLogInterval, if
+// non-zero, periodically logs each file's read/drop/rotation counters.
+// decoderFactory selects how lines are parsed - see decoderFactoryFor.
+func runMultiFileSource(ctx context.Context, logFilePattern, stateDir string, ignoreOlder time.Duration, fromBeginning bool, client *logging.Client, clusterName, teamProjectID string, auditQueueSize int, deadLetterPath string, rateLimitLinesPerSec float64, rateLimitBurst int, metricsLogInterval time.Duration, decoderFactory tailer.DecoderFactory) {
+	logger := slog.Default()
+
+	logEntries := make(chan map[string]interface{})
+	logLines := make(chan string)
+
+	var loggers sync.WaitGroup
+	loggers.Add(2)
+
+	auditLogger := auditlogger.NewAuditLogger(logEntries, clusterName, teamProjectID, client, logger, auditQueueSize, deadLetterPath)
+	go func() {
+		defer loggers.Done()
+		auditLogger.Log(ctx)
+	}()
+
+	fileLogger := filelogger.NewFileLogger(logLines, logger, false)
+	go func() {
+		defer loggers.Done()
+		fileLogger.Log(ctx)
+	}()
+
+	// Wait for the audit/file loggers to drain their queues before this
+	// function - and therefore main - returns, so a shutdown doesn't cut
+	// off in-flight audit entries.
+	defer loggers.Wait()
+
+	log.Printf("Tailing files matching %q", logFilePattern)
+
+	startPosition := tailer.StartAtCheckpoint
+	if fromBeginning {
+		startPosition = tailer.StartAtBeginning
+	}
+
+	checkpointStoreFor := func(filePath string) *checkpoint.Store {
+		if stateDir == "" {
+			return checkpoint.NewStore("", ignoreOlder)
+		}
+		stateFile := strings.ReplaceAll(filePath, string(filepath.Separator), "_") + ".state"
+		return checkpoint.NewStore(filepath.Join(stateDir, stateFile), ignoreOlder)
 	}
 
-	// Extract dbname from root level
-	if dbname, ok := logEntry["dbname"].(string); ok && dbname != "" {
-		labels["databaseName"] = dbname
+	var rateLimiterFor func(filePath string) *tailer.RateLimiter
+	if rateLimitLinesPerSec > 0 {
+		rateLimiterFor = func(filePath string) *tailer.RateLimiter {
+			return tailer.NewRateLimiter(rateLimitLinesPerSec, rateLimitBurst)
+		}
 	}
 
-	// Parse the AUDIT message to extract statement class
-	// Format: "AUDIT: SESSION,15,1,READ,SELECT,,,..."
-	// Fields: type, session_line, statement_id, class, command, ...
-	if message, ok := logEntry["message"].(string); ok {
-		// Split by comma after "AUDIT: "
-		auditPrefix := "AUDIT: "
-		if strings.HasPrefix(message, auditPrefix) {
-			auditData := strings.TrimPrefix(message, auditPrefix)
-			parts := strings.Split(auditData, ",")
-
-			// Extract audit type (SESSION, OBJECT, etc.) - index 0
-			if len(parts) > 0 && parts[0] != "" {
-				labels["auditType"] = parts[0]
-			}
-
-			// Extract statement class (READ, WRITE, etc.) - index 3
-			if len(parts) > 3 && parts[3] != "" {
-				labels["auditClass"] = parts[3]
-			}
-
-			// Extract command (SELECT, INSERT, UPDATE, DELETE, etc.) - index 4
-			if len(parts) > 4 && parts[4] != "" {
-				labels["command"] = parts[4]
-			}
+	quit := make(chan error, 1)
+	tailer.Watch(ctx, []string{logFilePattern}, logEntries, logLines, quit, logger, startPosition, checkpointStoreFor, decoderFactory, tailer.NDJSONAuditPredicate, tailer.DefaultRotatedNameFunc, rateLimiterFor, metricsLogInterval)
+
+	select {
+	case err := <-quit:
+		if err != nil {
+			log.Fatalf("Failed to tail files matching %q: %v", logFilePattern, err)
 		}
+	default:
 	}
+}
 
-	// Extract backend_type if present
-	if backendType, ok := logEntry["backend_type"].(string); ok && backendType != "" {
-		labels["backendType"] = backendType
-	}
+// runPodsSource streams Postgres pod logs directly from the Kubernetes API
+// instead of tailing a local file. It reconciles the set of pods matching
+// labelSelector and merges their log streams into the same logEntries/
+// logLines channels that auditlogger.AuditLogger and filelogger.FileLogger
+// consume, so AUDIT entries are shipped to GCP and everything else is
+// printed exactly as the file-based source does.
+func runPodsSource(ctx context.Context, k8sClient *kubernetes.Clientset, client *logging.Client, namespace, clusterName, teamProjectID, labelSelector string, auditQueueSize int, deadLetterPath string) {
+	logger := slog.Default()
 
-	// Create monitored resource with database_id and project_id
-	resource := &mrpb.MonitoredResource{
-		Type: "generic_node",
-		Labels: map[string]string{
-			"location":   "europe-north1",
-			"namespace":  "postgres-audit",
-			"node_id":    fmt.Sprintf("%s:%s", projectID, clusterName),
-			"project_id": projectID,
-		},
-	}
+	logEntries := make(chan map[string]interface{})
+	logLines := make(chan string)
 
-	entry := logging.Entry{
-		Payload:  string(entryJSON),
-		Severity: logging.Info,
-		Labels:   labels,
-		Resource: resource,
-	}
+	var loggers sync.WaitGroup
+	loggers.Add(2)
 
-	logger.Log(entry)
+	auditLogger := auditlogger.NewAuditLogger(logEntries, clusterName, teamProjectID, client, logger, auditQueueSize, deadLetterPath)
+	go func() {
+		defer loggers.Done()
+		auditLogger.Log(ctx)
+	}()
 
-	if err := logger.Flush(); err != nil {
-		return fmt.Errorf("failed to flush logger: %w", err)
-	}
+	fileLogger := filelogger.NewFileLogger(logLines, logger, false)
+	go func() {
+		defer loggers.Done()
+		fileLogger.Log(ctx)
+	}()
+
+	// Wait for the audit/file loggers to drain their queues before this
+	// function - and therefore main - returns, so a shutdown doesn't cut
+	// off in-flight audit entries.
+	defer loggers.Wait()
 
-	return nil
+	log.Printf("Streaming pod logs for pods matching %q in namespace %s", labelSelector, namespace)
+
+	source := podlogs.NewSource(k8sClient, namespace, labelSelector, logEntries, logLines)
+	if err := source.Run(ctx); err != nil && ctx.Err() == nil {
+		log.Fatalf("Failed to stream pod logs: %v", err)
+	}
 }
 
 func handleShutdown(cancel context.CancelFunc) {
@@ -345,32 +343,6 @@ func handleShutdown(cancel context.CancelFunc) {
 	cancel()
 }
 
-// checkLogRotation detects if the log file has been rotated
-// by comparing file stats (inode on Unix or size decrease)
-func checkLogRotation(filePath string, lastInfo os.FileInfo) (bool, error) {
-	if lastInfo == nil {
-		return false, nil
-	}
-
-	currentInfo, err := os.Stat(filePath)
-	if err != nil {
-		// File doesn't exist, might have been rotated and new one not created yet
-		return true, nil
-	}
-
-	// Check if it's a different file (different inode on Unix systems)
-	if !os.SameFile(lastInfo, currentInfo) {
-		return true, nil
-	}
-
-	// Check if file size decreased (indicates rotation/truncation)
-	if currentInfo.Size() < lastInfo.Size() {
-		return true, nil
-	}
-
-	return false, nil
-}
-
 func getPodInfo(client *kubernetes.Clientset) (namespace, clusterName string, err error) {
 	podName := os.Getenv("POD_NAME")
 	if podName == "" {
@@ -424,61 +396,27 @@ func getK8sClient() (*kubernetes.Clientset, error) {
 	return clientset, nil
 }
 
-// testReadLastEntries reads approximately the last N entries from the file for debugging
-func testReadLastEntries(file *os.File, n int) {
+// testReadLastEntries reads the last n entries from logFilePath via
+// tailer.ReadLogs and prints them, for debugging.
+func testReadLastEntries(logFilePath string, n int, decoderFactory tailer.DecoderFactory) {
 	log.Printf("Test mode: attempting to read last %d entries from file", n)
 
-	// Get file size
-	info, err := file.Stat()
-	if err != nil {
-		log.Fatalf("Failed to stat file: %v", err)
-	}
+	t := tailer.NewTailer(logFilePath, nil, nil, slog.Default(), tailer.StartAtEnd, nil, decoderFactory, tailer.NDJSONAuditPredicate, tailer.DefaultRotatedNameFunc, nil)
+	watcher := t.ReadLogs(context.Background(), tailer.ReadConfig{Tail: n})
 
-	fileSize := info.Size()
-	log.Printf("File size: %d bytes", fileSize)
-
-	// Start from a position that's likely to contain the last N entries
-	// Assume average entry is ~500 bytes, seek back n*1000 bytes to be safe
-	seekPos := fileSize - int64(n*1000)
-	if seekPos < 0 {
-		seekPos = 0
-	}
-
-	if _, err := file.Seek(seekPos, 0); err != nil {
-		log.Fatalf("Failed to seek: %v", err)
-	}
-
-	log.Printf("Seeking to position: %d", seekPos)
-
-	decoder := json.NewDecoder(file)
-	entries := make([]map[string]interface{}, 0, n)
-
-	// Read all entries from this position
-	for {
-		var entry map[string]interface{}
-		if err := decoder.Decode(&entry); err != nil {
-			if err == io.EOF {
-				break
-			}
-			log.Printf("Decode error: %v", err)
-			// Try to skip to next line
-			continue
-		}
+	var entries []tailer.Entry
+	for entry := range watcher.Msg {
 		entries = append(entries, entry)
 	}
-
-	totalRead := len(entries)
-	log.Printf("Read %d total entries from position %d", totalRead, seekPos)
-
-	// Print the last N entries
-	start := 0
-	if totalRead > n {
-		start = totalRead - n
+	select {
+	case err := <-watcher.Err:
+		log.Fatalf("Failed to read last entries: %v", err)
+	default:
 	}
 
-	log.Printf("Displaying last %d entries:", len(entries[start:]))
-	for i, entry := range entries[start:] {
-		jsonBytes, _ := json.MarshalIndent(entry, "", "  ")
+	log.Printf("Displaying last %d entries:", len(entries))
+	for i, entry := range entries {
+		jsonBytes, _ := json.MarshalIndent(entry.Fields, "", "  ")
 		log.Printf("\n=== Entry %d ===\n%s\n", i+1, string(jsonBytes))
 	}
 }
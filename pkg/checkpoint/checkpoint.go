@@ -0,0 +1,188 @@
+// Package checkpoint persists a file read-offset to disk so the tailer can
+// resume from where it left off across restarts instead of always seeking
+// to end-of-file, mirroring the harvester/registry pattern used by
+// filebeat-style tailers.
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// State is the on-disk record of how far we've read into a given file.
+// A file is identified by its device/inode pair rather than its path, so
+// renames and rotations don't get confused with the original file.
+type State struct {
+	Device    uint64    `json:"device"`
+	Inode     uint64    `json:"inode"`
+	Offset    int64     `json:"offset"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Store reads and writes a State to a single state file on disk.
+type Store struct {
+	path        string
+	ignoreOlder time.Duration
+
+	mu    sync.Mutex
+	state State
+}
+
+// NewStore returns a Store backed by the state file at path. ignoreOlder, if
+// non-zero, causes Load to discard checkpoints for files whose mtime is
+// older than that duration, since such files are presumably already fully
+// processed and shipped off by log rotation tooling.
+func NewStore(path string, ignoreOlder time.Duration) *Store {
+	return &Store{
+		path:        path,
+		ignoreOlder: ignoreOlder,
+	}
+}
+
+// Load reads the state file, if any, and returns the recorded offset for
+// file if it still matches the checkpointed device/inode. It returns
+// (0, false, nil) if there is no usable checkpoint for file.
+func (s *Store) Load(file *os.File) (offset int64, ok bool, err error) {
+	if s.path == "" {
+		return 0, false, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return 0, false, fmt.Errorf("failed to parse state file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	if s.ignoreOlder > 0 && time.Since(info.ModTime()) > s.ignoreOlder {
+		return 0, false, nil
+	}
+
+	device, inode, err := fileIdentity(info)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to determine file identity: %w", err)
+	}
+
+	if state.Device != device || state.Inode != inode {
+		return 0, false, nil
+	}
+
+	if state.Offset > info.Size() {
+		// The file is smaller than our last known offset - it was
+		// truncated in place rather than rotated away, so there is
+		// nothing sensible to resume from.
+		return 0, false, nil
+	}
+
+	s.mu.Lock()
+	s.state = state
+	s.mu.Unlock()
+
+	return state.Offset, true, nil
+}
+
+// Save records the current offset into file, identified by its
+// device/inode, and fsyncs it to a temporary file before atomically
+// renaming it over the state file so a crash mid-write never leaves a
+// corrupt or partially-written checkpoint behind.
+func (s *Store) Save(file *os.File, offset int64) error {
+	if s.path == "" {
+		return nil
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	device, inode, err := fileIdentity(info)
+	if err != nil {
+		return fmt.Errorf("failed to determine file identity: %w", err)
+	}
+
+	state := State{
+		Device:    device,
+		Inode:     inode,
+		Offset:    offset,
+		UpdatedAt: time.Now(),
+	}
+
+	if err := s.write(state); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.state = state
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Reset records offset zero for file, identified by its device/inode. It is
+// used when a log rotation is detected, so the new file starts scanning
+// from the beginning rather than inheriting the previous file's offset.
+func (s *Store) Reset(file *os.File) error {
+	return s.Save(file, 0)
+}
+
+func (s *Store) write(state State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	dir := filepath.Dir(s.path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary state file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to fsync state file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to close state file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to rename state file into place: %w", err)
+	}
+
+	return nil
+}
+
+func fileIdentity(info os.FileInfo) (device, inode uint64, err error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, fmt.Errorf("unsupported platform: cannot determine device/inode for %s", info.Name())
+	}
+	return uint64(stat.Dev), stat.Ino, nil
+}
@@ -0,0 +1,249 @@
+package checkpoint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(logPath, []byte("hello world\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	logFile, err := os.Open(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logFile.Close()
+
+	store := NewStore(filepath.Join(dir, "app.state"), 0)
+
+	if err := store.Save(logFile, 6); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	offset, ok, err := store.Load(logFile)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !ok || offset != 6 {
+		t.Fatalf("Load() = (%d, %v), want (6, true)", offset, ok)
+	}
+}
+
+// TestResumeAfterRestart simulates a crash/restart: a second, independent
+// Store pointed at the same state file must be able to resume from the
+// offset the first Store saved, since that's the whole point of checkpointing
+// across process restarts.
+func TestResumeAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(logPath, []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	statePath := filepath.Join(dir, "app.state")
+
+	logFile, err := os.Open(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logFile.Close()
+
+	first := NewStore(statePath, 0)
+	if err := first.Save(logFile, 4); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	second := NewStore(statePath, 0)
+	offset, ok, err := second.Load(logFile)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !ok || offset != 4 {
+		t.Fatalf("Load() on a fresh Store = (%d, %v), want (4, true)", offset, ok)
+	}
+}
+
+func TestLoadNoStateFile(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(logPath, []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	logFile, err := os.Open(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logFile.Close()
+
+	store := NewStore(filepath.Join(dir, "missing.state"), 0)
+	offset, ok, err := store.Load(logFile)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if ok || offset != 0 {
+		t.Fatalf("Load() = (%d, %v), want (0, false) when no state file exists", offset, ok)
+	}
+}
+
+func TestLoadRejectsDifferentFile(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(dir, "app.state")
+
+	oldPath := filepath.Join(dir, "old.log")
+	if err := os.WriteFile(oldPath, []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	oldFile, err := os.Open(oldPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer oldFile.Close()
+
+	store := NewStore(statePath, 0)
+	if err := store.Save(oldFile, 5); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	newPath := filepath.Join(dir, "new.log")
+	if err := os.WriteFile(newPath, []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	newFile, err := os.Open(newPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer newFile.Close()
+
+	offset, ok, err := store.Load(newFile)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if ok || offset != 0 {
+		t.Fatalf("Load() for a different file = (%d, %v), want (0, false) - device/inode mismatch must not resume", offset, ok)
+	}
+}
+
+func TestLoadRejectsOffsetBeyondTruncatedFile(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(logPath, []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	statePath := filepath.Join(dir, "app.state")
+
+	logFile, err := os.Open(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logFile.Close()
+
+	store := NewStore(statePath, 0)
+	if err := store.Save(logFile, 10); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if err := os.WriteFile(logPath, []byte("12"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	offset, ok, err := store.Load(logFile)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if ok || offset != 0 {
+		t.Fatalf("Load() after truncation = (%d, %v), want (0, false)", offset, ok)
+	}
+}
+
+func TestLoadIgnoresOldCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(logPath, []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(logPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+	statePath := filepath.Join(dir, "app.state")
+
+	logFile, err := os.Open(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logFile.Close()
+
+	store := NewStore(statePath, time.Minute)
+	if err := store.Save(logFile, 3); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	offset, ok, err := store.Load(logFile)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if ok || offset != 0 {
+		t.Fatalf("Load() for a file older than ignoreOlder = (%d, %v), want (0, false)", offset, ok)
+	}
+}
+
+func TestReset(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(logPath, []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	statePath := filepath.Join(dir, "app.state")
+
+	logFile, err := os.Open(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logFile.Close()
+
+	store := NewStore(statePath, 0)
+	if err := store.Save(logFile, 10); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := store.Reset(logFile); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+
+	offset, ok, err := store.Load(logFile)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !ok || offset != 0 {
+		t.Fatalf("Load() after Reset = (%d, %v), want (0, true)", offset, ok)
+	}
+}
+
+func TestNoopStoreWithEmptyPath(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(logPath, []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	logFile, err := os.Open(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logFile.Close()
+
+	store := NewStore("", 0)
+	if err := store.Save(logFile, 3); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	offset, ok, err := store.Load(logFile)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if ok || offset != 0 {
+		t.Fatalf("Load() with an empty path = (%d, %v), want (0, false) - it must be a no-op", offset, ok)
+	}
+}
@@ -0,0 +1,85 @@
+// Package pgaudit parses the CSV payload that pgAudit appends to the
+// "AUDIT: " prefix of a log message, honouring pgAudit's CSV quoting rules
+// so commas and newlines embedded in a statement or parameter list don't
+// get mistaken for field separators.
+package pgaudit
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+// messagePrefix is the literal prefix pgAudit puts in front of the CSV
+// payload in a log line's "message" field.
+const messagePrefix = "AUDIT: "
+
+// Record is a single parsed pgAudit log entry, one field per CSV column in
+// the order pgAudit emits them.
+type Record struct {
+	AuditType       string
+	StatementID     string
+	SubstatementID  string
+	Class           string
+	Command         string
+	ObjectType      string
+	ObjectName      string
+	Statement       string
+	Parameters      string
+	Rows            string
+	ApplicationName string
+}
+
+// HasPrefix reports whether message looks like a pgAudit log line, i.e.
+// whether it starts with the "AUDIT: " prefix Parse expects.
+func HasPrefix(message string) bool {
+	return strings.HasPrefix(message, messagePrefix)
+}
+
+// Parse parses message's pgAudit CSV payload into a Record. message is
+// expected to carry the full "AUDIT: ..." log message; the prefix is
+// stripped before parsing. Double-quoted fields (with "" escaping) may
+// contain embedded commas and newlines, matching the CSV grammar pgAudit
+// itself writes with.
+func Parse(message string) (Record, error) {
+	data, ok := strings.CutPrefix(message, messagePrefix)
+	if !ok {
+		return Record{}, fmt.Errorf("message does not have the %q prefix", messagePrefix)
+	}
+
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.FieldsPerRecord = -1
+	// pgAudit's own escaping isn't always strictly well-formed (e.g. stray
+	// quotes inside an unquoted field), so don't fail the whole record over it.
+	reader.LazyQuotes = true
+
+	fields, err := reader.Read()
+	if err != nil {
+		return Record{}, fmt.Errorf("failed to parse pgAudit CSV record: %w", err)
+	}
+
+	return recordFromFields(fields), nil
+}
+
+func recordFromFields(fields []string) Record {
+	field := func(i int) string {
+		if i < len(fields) {
+			return fields[i]
+		}
+		return ""
+	}
+
+	return Record{
+		AuditType:       field(0),
+		StatementID:     field(1),
+		SubstatementID:  field(2),
+		Class:           field(3),
+		Command:         field(4),
+		ObjectType:      field(5),
+		ObjectName:      field(6),
+		Statement:       field(7),
+		Parameters:      field(8),
+		Rows:            field(9),
+		ApplicationName: field(10),
+	}
+}
@@ -0,0 +1,110 @@
+package pgaudit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    Record
+	}{
+		{
+			name:    "session record",
+			message: `AUDIT: SESSION,15,1,READ,SELECT,TABLE,public.accounts,"SELECT * FROM public.accounts WHERE id = 1",,1,psql`,
+			want: Record{
+				AuditType:       "SESSION",
+				StatementID:     "15",
+				SubstatementID:  "1",
+				Class:           "READ",
+				Command:         "SELECT",
+				ObjectType:      "TABLE",
+				ObjectName:      "public.accounts",
+				Statement:       "SELECT * FROM public.accounts WHERE id = 1",
+				Parameters:      "",
+				Rows:            "1",
+				ApplicationName: "psql",
+			},
+		},
+		{
+			name:    "object record",
+			message: `AUDIT: OBJECT,20,1,DDL,CREATE TABLE,TABLE,public.new_table,"CREATE TABLE public.new_table (id int)",,0,migrate`,
+			want: Record{
+				AuditType:       "OBJECT",
+				StatementID:     "20",
+				SubstatementID:  "1",
+				Class:           "DDL",
+				Command:         "CREATE TABLE",
+				ObjectType:      "TABLE",
+				ObjectName:      "public.new_table",
+				Statement:       "CREATE TABLE public.new_table (id int)",
+				Parameters:      "",
+				Rows:            "0",
+				ApplicationName: "migrate",
+			},
+		},
+		{
+			name: "multi-line statement",
+			message: "AUDIT: SESSION,21,1,WRITE,INSERT,TABLE,public.events,\"INSERT INTO public.events (payload)\nVALUES ('x')\",,1,app",
+			want: Record{
+				AuditType:       "SESSION",
+				StatementID:     "21",
+				SubstatementID:  "1",
+				Class:           "WRITE",
+				Command:         "INSERT",
+				ObjectType:      "TABLE",
+				ObjectName:      "public.events",
+				Statement:       "INSERT INTO public.events (payload)\nVALUES ('x')",
+				Parameters:      "",
+				Rows:            "1",
+				ApplicationName: "app",
+			},
+		},
+		{
+			name:    "quoted identifier with embedded comma",
+			message: `AUDIT: SESSION,22,1,READ,SELECT,TABLE,public.t,"SELECT a, ""b,c"" FROM t WHERE x IN ('a,b')",,2,app`,
+			want: Record{
+				AuditType:       "SESSION",
+				StatementID:     "22",
+				SubstatementID:  "1",
+				Class:           "READ",
+				Command:         "SELECT",
+				ObjectType:      "TABLE",
+				ObjectName:      "public.t",
+				Statement:       `SELECT a, "b,c" FROM t WHERE x IN ('a,b')`,
+				Parameters:      "",
+				Rows:            "2",
+				ApplicationName: "app",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.message)
+			if err != nil {
+				t.Fatalf("Parse() returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMissingPrefix(t *testing.T) {
+	if _, err := Parse("not an audit message"); err == nil {
+		t.Fatal("expected an error for a message without the AUDIT: prefix")
+	}
+}
+
+func TestHasPrefix(t *testing.T) {
+	if !HasPrefix("AUDIT: SESSION,1,1,READ,SELECT,,,,,,") {
+		t.Error("HasPrefix() = false, want true for an AUDIT: message")
+	}
+	if HasPrefix("not an audit message") {
+		t.Error("HasPrefix() = true, want false for a non-AUDIT message")
+	}
+}
@@ -0,0 +1,312 @@
+// Package podlogs streams Postgres pod container logs directly from the
+// Kubernetes API, as an alternative to tailing a local log file. It keeps
+// the set of streamed pods in sync with the cluster by watching for pod
+// add/remove events, and merges every matching pod's log stream into the
+// same logEntries/logLines channels that internal/auditlogger and
+// internal/filelogger already consume.
+package podlogs
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// maxScanTokenSize bounds how large a single log line can be, matching
+	// the maxScanTokenSize internal/tailer's scanner uses.
+	maxScanTokenSize = 1024 * 1024
+
+	// streamRetryInitialDelay is the starting backoff before reconnecting a
+	// pod's log stream after a recoverable error (connection reset, pod
+	// restart, API server hiccup).
+	streamRetryInitialDelay = 1 * time.Second
+	// streamRetryMaxDelay caps the exponential backoff between reconnects.
+	streamRetryMaxDelay = 30 * time.Second
+
+	// drainGrace is how long a deleted pod's stream is given to hit EOF on
+	// its own, carrying over any log lines still buffered server-side,
+	// before it is force-cancelled.
+	drainGrace = 10 * time.Second
+)
+
+// Source streams container logs from every pod matching a label selector
+// and routes parsed audit entries and raw lines into the shared downstream
+// channels, mirroring what the file-based tailer produces.
+type Source struct {
+	client        kubernetes.Interface
+	namespace     string
+	labelSelector string
+
+	logEntries chan<- map[string]interface{}
+	logLines   chan<- string
+
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewSource returns a Source that streams logs for pods in namespace
+// matching labelSelector, delivering parsed AUDIT entries on logEntries and
+// everything else as raw lines on logLines.
+func NewSource(client kubernetes.Interface, namespace, labelSelector string, logEntries chan<- map[string]interface{}, logLines chan<- string) *Source {
+	return &Source{
+		client:        client,
+		namespace:     namespace,
+		labelSelector: labelSelector,
+		logEntries:    logEntries,
+		logLines:      logLines,
+		cancels:       make(map[string]context.CancelFunc),
+	}
+}
+
+// Run watches for pods matching the label selector, starting a per-pod
+// streaming goroutine for each running pod it sees and stopping it once the
+// pod is deleted. It blocks until ctx is cancelled, at which point it waits
+// for every in-flight stream to return before returning itself.
+func (s *Source) Run(ctx context.Context) error {
+	watcher, err := s.client.CoreV1().Pods(s.namespace).Watch(ctx, metav1.ListOptions{LabelSelector: s.labelSelector})
+	if err != nil {
+		return fmt.Errorf("failed to watch pods matching %q: %w", s.labelSelector, err)
+	}
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.wg.Wait()
+			return nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				s.wg.Wait()
+				return fmt.Errorf("pod watch channel closed unexpectedly")
+			}
+			s.handleEvent(ctx, event)
+		}
+	}
+}
+
+func (s *Source) handleEvent(ctx context.Context, event watch.Event) {
+	pod, ok := event.Object.(*corev1.Pod)
+	if !ok {
+		return
+	}
+
+	switch event.Type {
+	case watch.Added, watch.Modified:
+		if pod.Status.Phase == corev1.PodRunning {
+			s.startStreaming(ctx, pod.Name)
+		}
+	case watch.Deleted:
+		s.stopStreaming(pod.Name)
+	}
+}
+
+// startStreaming begins tailing podName's logs in its own goroutine, unless
+// it is already being streamed.
+func (s *Source) startStreaming(ctx context.Context, podName string) {
+	s.mu.Lock()
+	if _, ok := s.cancels[podName]; ok {
+		s.mu.Unlock()
+		return
+	}
+	podCtx, cancel := context.WithCancel(ctx)
+	s.cancels[podName] = cancel
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			s.mu.Lock()
+			delete(s.cancels, podName)
+			s.mu.Unlock()
+		}()
+		s.streamPod(podCtx, podName)
+	}()
+}
+
+// stopStreaming lets podName's stream drain whatever it already has
+// buffered before cancelling it, rather than tearing it down the instant
+// the pod disappears.
+func (s *Source) stopStreaming(podName string) {
+	s.mu.Lock()
+	cancel, ok := s.cancels[podName]
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	go func() {
+		time.Sleep(drainGrace)
+		cancel()
+	}()
+}
+
+// streamPod opens a follow-mode log stream for podName and reconnects with
+// exponential backoff on recoverable errors, resuming from the timestamp of
+// the last line it saw, so lines carrying that timestamp may be delivered
+// again after a reconnect.
+func (s *Source) streamPod(ctx context.Context, podName string) {
+	since := time.Now()
+	delay := streamRetryInitialDelay
+
+	for ctx.Err() == nil {
+		sinceTime := metav1.NewTime(since)
+		stream, err := s.client.CoreV1().Pods(s.namespace).GetLogs(podName, &corev1.PodLogOptions{
+			Follow:     true,
+			Timestamps: true,
+			SinceTime:  &sinceTime,
+		}).Stream(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("podlogs: failed to open log stream for pod %s, retrying in %s: %v", podName, delay, err)
+			if !sleepUnlessCancelled(ctx, delay) {
+				return
+			}
+			delay = nextBackoff(delay)
+			continue
+		}
+
+		delay = streamRetryInitialDelay
+		if last, ok := s.consume(ctx, podName, stream); ok {
+			since = last
+		}
+		stream.Close()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Stream() returning here (EOF, connection reset, pod restart)
+		// while the pod is still running is recoverable - reconnect from
+		// the timestamp of the last line we processed.
+		log.Printf("podlogs: log stream for pod %s ended, reconnecting", podName)
+		if !sleepUnlessCancelled(ctx, delay) {
+			return
+		}
+		delay = nextBackoff(delay)
+	}
+}
+
+// consume reads lines from stream until it closes, forwarding parsed AUDIT
+// entries and raw lines to the shared channels. It returns the timestamp of
+// the last line it saw, so the caller can resume from there on reconnect.
+func (s *Source) consume(ctx context.Context, podName string, stream io.Reader) (time.Time, bool) {
+	scanner := bufio.NewScanner(stream)
+	buf := make([]byte, maxScanTokenSize)
+	scanner.Buffer(buf, maxScanTokenSize)
+
+	var last time.Time
+	var sawLine bool
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		ts, rest, ok := splitTimestamp(line)
+		if ok {
+			last = ts
+			sawLine = true
+			line = rest
+		}
+
+		if !s.publish(ctx, line) {
+			return last, sawLine
+		}
+	}
+
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		log.Printf("podlogs: error reading log stream for pod %s: %v", podName, err)
+	}
+
+	return last, sawLine
+}
+
+// publish parses line as a JSON log entry and routes it the same way the
+// file-based tailer does: AUDIT entries go to logEntries, everything else
+// is forwarded as a raw line. It reports false if ctx is cancelled, so a
+// caller still trying to publish at shutdown doesn't block forever waiting
+// for auditlogger/filelogger to receive - they both stop reading from these
+// channels the moment ctx is done.
+func (s *Source) publish(ctx context.Context, line string) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	default:
+	}
+
+	if line == "" {
+		return true
+	}
+
+	var logEntry map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &logEntry); err != nil {
+		log.Printf("podlogs: failed to parse JSON log line: %v", err)
+		return true
+	}
+
+	if message, ok := logEntry["message"].(string); ok && strings.HasPrefix(message, "AUDIT:") {
+		select {
+		case <-ctx.Done():
+			return false
+		case s.logEntries <- logEntry:
+		}
+	} else {
+		select {
+		case <-ctx.Done():
+			return false
+		case s.logLines <- line:
+		}
+	}
+	return true
+}
+
+// splitTimestamp strips the RFC3339Nano timestamp that Kubernetes prefixes
+// to each line when PodLogOptions.Timestamps is set, returning it alongside
+// the remainder of the line.
+func splitTimestamp(line string) (time.Time, string, bool) {
+	prefix, rest, ok := strings.Cut(line, " ")
+	if !ok {
+		return time.Time{}, line, false
+	}
+
+	ts, err := time.Parse(time.RFC3339Nano, prefix)
+	if err != nil {
+		return time.Time{}, line, false
+	}
+
+	return ts, rest, true
+}
+
+// nextBackoff doubles delay, capped at streamRetryMaxDelay.
+func nextBackoff(delay time.Duration) time.Duration {
+	delay *= 2
+	if delay > streamRetryMaxDelay {
+		delay = streamRetryMaxDelay
+	}
+	return delay
+}
+
+// sleepUnlessCancelled waits for delay, returning false if ctx is
+// cancelled first.
+func sleepUnlessCancelled(ctx context.Context, delay time.Duration) bool {
+	select {
+	case <-time.After(delay):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
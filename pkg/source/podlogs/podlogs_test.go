@@ -0,0 +1,110 @@
+package podlogs
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSplitTimestamp(t *testing.T) {
+	ts, rest, ok := splitTimestamp(`2026-01-01T00:00:00.123456789Z {"message":"AUDIT: hi"}`)
+	if !ok {
+		t.Fatal("splitTimestamp() ok = false, want true")
+	}
+	want := time.Date(2026, 1, 1, 0, 0, 0, 123456789, time.UTC)
+	if !ts.Equal(want) {
+		t.Errorf("ts = %v, want %v", ts, want)
+	}
+	if rest != `{"message":"AUDIT: hi"}` {
+		t.Errorf("rest = %q, want the line with the timestamp prefix stripped", rest)
+	}
+}
+
+func TestSplitTimestampNoTimestamp(t *testing.T) {
+	_, rest, ok := splitTimestamp("not timestamped")
+	if ok {
+		t.Error("splitTimestamp() ok = true, want false for a line with no leading timestamp")
+	}
+	if rest != "not timestamped" {
+		t.Errorf("rest = %q, want the line unchanged", rest)
+	}
+}
+
+func TestNextBackoff(t *testing.T) {
+	delay := streamRetryInitialDelay
+	for i := 0; i < 10; i++ {
+		delay = nextBackoff(delay)
+		if delay > streamRetryMaxDelay {
+			t.Fatalf("nextBackoff() = %v, exceeds cap %v", delay, streamRetryMaxDelay)
+		}
+	}
+	if delay != streamRetryMaxDelay {
+		t.Errorf("nextBackoff() after repeated doubling = %v, want it to have saturated at %v", delay, streamRetryMaxDelay)
+	}
+}
+
+func TestSleepUnlessCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if sleepUnlessCancelled(ctx, time.Second) {
+		t.Error("sleepUnlessCancelled() = true, want false once ctx is already cancelled")
+	}
+
+	if !sleepUnlessCancelled(context.Background(), time.Millisecond) {
+		t.Error("sleepUnlessCancelled() = false, want true when ctx is never cancelled")
+	}
+}
+
+func TestPublishStopsOnCancelledContext(t *testing.T) {
+	logEntries := make(chan map[string]interface{})
+	s := &Source{logEntries: logEntries, logLines: make(chan string)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if s.publish(ctx, `{"message":"AUDIT: hi"}`) {
+		t.Error("publish() = true, want false when ctx is already cancelled")
+	}
+}
+
+func TestPublishRoutesAuditAndPlainLines(t *testing.T) {
+	logEntries := make(chan map[string]interface{}, 1)
+	logLines := make(chan string, 1)
+	s := &Source{logEntries: logEntries, logLines: logLines}
+
+	if !s.publish(context.Background(), `{"message":"AUDIT: hi"}`) {
+		t.Fatal("publish() = false, want true")
+	}
+	select {
+	case entry := <-logEntries:
+		if entry["message"] != "AUDIT: hi" {
+			t.Errorf("logEntries got %v, want message %q", entry, "AUDIT: hi")
+		}
+	default:
+		t.Fatal("expected an AUDIT line to be routed to logEntries")
+	}
+
+	if !s.publish(context.Background(), `{"message":"not audit"}`) {
+		t.Fatal("publish() = false, want true")
+	}
+	select {
+	case line := <-logLines:
+		if line != `{"message":"not audit"}` {
+			t.Errorf("logLines got %q", line)
+		}
+	default:
+		t.Fatal("expected a non-AUDIT line to be routed to logLines")
+	}
+}
+
+func TestPublishIgnoresEmptyAndInvalidLines(t *testing.T) {
+	s := &Source{logEntries: make(chan map[string]interface{}), logLines: make(chan string)}
+
+	if !s.publish(context.Background(), "") {
+		t.Error("publish() = false, want true for an empty line (nothing to send, not cancelled)")
+	}
+	if !s.publish(context.Background(), "not json") {
+		t.Error("publish() = false, want true for an unparseable line (logged and dropped, not cancelled)")
+	}
+}